@@ -0,0 +1,228 @@
+package picturebook
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aaronland/go-picturebook/bucket"
+)
+
+// type BatchResult defines a struct containing the outcome of creating a single picturebook in batch mode.
+type BatchResult struct {
+	// The line number of the job in the batch file.
+	Line int
+	// The filename of the picturebook.
+	Filename string
+	// The time it took to create the picturebook.
+	Duration time.Duration
+	// The error, if any, reported while creating the picturebook.
+	Error error
+}
+
+// runBatch creates a picturebook for each job in the JSON Lines file defined by `app_opts.Batch`. Each line is a JSON-encoded
+// `RunOptions` object whose properties are applied on top of 'app_opts', so that flags passed on the command line act as defaults
+// for every job. Jobs are run in order and a failed job does not stop the remaining jobs from being run. If `CacheBucketURI` is
+// empty a temporary cache, shared by all the jobs, is used. A summary of every job is written to STDOUT once they have all been run.
+func runBatch(ctx context.Context, app_opts *RunOptions) error {
+
+	if app_opts.Watch {
+		return fmt.Errorf("The -batch and -watch flags can not be used together")
+	}
+
+	batch_opts := app_opts.Clone()
+	batch_opts.Batch = ""
+
+	if batch_opts.CacheBucketURI == "" {
+
+		cache_root, err := os.MkdirTemp("", "picturebook-cache-")
+
+		if err != nil {
+			return fmt.Errorf("Failed to create temporary cache, %w", err)
+		}
+
+		defer os.RemoveAll(cache_root)
+
+		batch_opts.CacheBucketURI = fmt.Sprintf("file://%s", cache_root)
+	}
+
+	r, err := os.Open(app_opts.Batch)
+
+	if err != nil {
+		return fmt.Errorf("Failed to open %s, %w", app_opts.Batch, err)
+	}
+
+	defer r.Close()
+
+	results, err := RunBatch(ctx, batch_opts, r)
+
+	if err != nil {
+		return err
+	}
+
+	err = WriteBatchSummary(os.Stdout, results)
+
+	if err != nil {
+		return fmt.Errorf("Failed to write batch summary, %w", err)
+	}
+
+	failed := 0
+
+	for _, res := range results {
+		if res.Error != nil {
+			failed += 1
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d picturebooks failed", failed, len(results))
+	}
+
+	return nil
+}
+
+// RunBatch creates a picturebook for each JSON-encoded `RunOptions` object, one per line, read from 'r'. The properties of each
+// object are applied on top of 'defaults'. Empty lines are ignored. Buckets are opened once, and shared by every job which uses
+// them, and closed once all the jobs have been run. An error is only returned if 'r' can not be read; errors creating individual
+// picturebooks are recorded in the list of `BatchResult` instances returned, one per job, in order.
+func RunBatch(ctx context.Context, defaults *RunOptions, r io.Reader) ([]*BatchResult, error) {
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to register gocloud buckets, %w", err)
+	}
+
+	buckets := NewBuckets()
+
+	defer func() {
+
+		err := buckets.Close()
+
+		if err != nil {
+			slog.Warn("Failed to close buckets", "error", err)
+		}
+	}()
+
+	results := make([]*BatchResult, 0)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	line := 0
+
+	for scanner.Scan() {
+
+		line += 1
+
+		txt := strings.TrimSpace(scanner.Text())
+
+		if txt == "" {
+			continue
+		}
+
+		logger := slog.Default()
+		logger = logger.With("line", line)
+
+		res := &BatchResult{
+			Line: line,
+		}
+
+		results = append(results, res)
+
+		opts, err := batchJobOptions(defaults, txt)
+
+		if err != nil {
+			logger.Error("Invalid batch job", "error", err)
+			res.Error = err
+			continue
+		}
+
+		res.Filename = opts.Filename
+
+		logger.Info("Create picturebook", "filename", opts.Filename)
+
+		t1 := time.Now()
+		err = RunWithBuckets(ctx, opts, buckets)
+		res.Duration = time.Since(t1)
+
+		if err != nil {
+			logger.Error("Failed to create picturebook", "filename", opts.Filename, "error", err)
+			res.Error = err
+			continue
+		}
+	}
+
+	err = scanner.Err()
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read batch jobs, %w", err)
+	}
+
+	return results, nil
+}
+
+// WriteBatchSummary writes a table summarizing 'results' to 'wr'.
+func WriteBatchSummary(wr io.Writer, results []*BatchResult) error {
+
+	tw := tabwriter.NewWriter(wr, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "LINE\tFILENAME\tSTATUS\tTIME\t")
+
+	ok := 0
+
+	for _, res := range results {
+
+		status := "ok"
+
+		if res.Error != nil {
+			status = fmt.Sprintf("failed: %v", res.Error)
+		} else {
+			ok += 1
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t\n", res.Line, res.Filename, status, res.Duration.Round(time.Millisecond))
+	}
+
+	err := tw.Flush()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(wr, "\n%d of %d picturebooks created\n", ok, len(results))
+	return err
+}
+
+// batchJobOptions returns a new `RunOptions` instance derived from 'defaults' and the JSON-encoded job in 'txt'.
+func batchJobOptions(defaults *RunOptions, txt string) (*RunOptions, error) {
+
+	opts := defaults.Clone()
+
+	dec := json.NewDecoder(strings.NewReader(txt))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(opts)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode job, %w", err)
+	}
+
+	switch {
+	case opts.Batch != "":
+		return nil, fmt.Errorf("Batch jobs can not define their own batch file")
+	case opts.Watch:
+		return nil, fmt.Errorf("Batch jobs can not watch sources for changes")
+	case opts.Filename == STDOUT:
+		return nil, fmt.Errorf("Batch jobs can not write picturebooks to STDOUT")
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,124 @@
+package picturebook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestRunBatch(t *testing.T) {
+
+	ctx := context.Background()
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to register buckets, %v", err)
+	}
+
+	source := t.TempDir()
+	target := t.TempDir()
+
+	im := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	im.Set(10, 10, color.White)
+
+	wr, err := os.Create(filepath.Join(source, "test.png"))
+
+	if err != nil {
+		t.Fatalf("Failed to create image, %v", err)
+	}
+
+	err = png.Encode(wr, im)
+
+	if err != nil {
+		t.Fatalf("Failed to encode image, %v", err)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close image, %v", err)
+	}
+
+	defaults, err := RunOptionsFromArgs(ctx, []string{
+		"-progress-monitor-uri", "null://",
+		"-target-uri", fmt.Sprintf("file://%s?metadata=skip", target),
+		"-cache-uri", fmt.Sprintf("file://%s", t.TempDir()),
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to derive default options, %v", err)
+	}
+
+	jobs := []string{
+		fmt.Sprintf(`{"Sources": ["%s"], "Filename": "one.pdf"}`, source),
+		``,
+		fmt.Sprintf(`{"Sources": ["%s"], "Filename": "two.pdf", "Size": "a5"}`, source),
+		`{"Filename": "-"}`,
+		`{"Unknown": true}`,
+	}
+
+	results, err := RunBatch(ctx, defaults, strings.NewReader(strings.Join(jobs, "\n")))
+
+	if err != nil {
+		t.Fatalf("Failed to run batch, %v", err)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("Unexpected number of results: %d", len(results))
+	}
+
+	expected := []struct {
+		line int
+		ok   bool
+	}{
+		{1, true},
+		{3, true},
+		{4, false},
+		{5, false},
+	}
+
+	for idx, e := range expected {
+
+		res := results[idx]
+
+		if res.Line != e.line {
+			t.Fatalf("Unexpected line for result %d: %d", idx, res.Line)
+		}
+
+		if (res.Error == nil) != e.ok {
+			t.Fatalf("Unexpected outcome for line %d: %v", res.Line, res.Error)
+		}
+	}
+
+	for _, fname := range []string{"one.pdf", "two.pdf"} {
+
+		_, err := os.Stat(filepath.Join(target, fname))
+
+		if err != nil {
+			t.Fatalf("Expected %s to be created, %v", fname, err)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	err = WriteBatchSummary(&buf, results)
+
+	if err != nil {
+		t.Fatalf("Failed to write summary, %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "2 of 4 picturebooks created") {
+		t.Fatalf("Unexpected summary: %s", buf.String())
+	}
+}
@@ -0,0 +1,72 @@
+package picturebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aaronland/go-picturebook/bucket"
+)
+
+// type Buckets defines a struct for opening, and sharing, the buckets used to create one or more picturebooks. Buckets
+// are keyed by their URI so that picturebooks which read from, or write to, the same location share a single `bucket.Bucket`
+// instance. It is safe for concurrent use.
+type Buckets struct {
+	buckets map[string]bucket.Bucket
+	mu      *sync.Mutex
+}
+
+// NewBuckets returns a new, empty, `Buckets` instance. The `Close` method should be called once the buckets are no longer needed.
+func NewBuckets() *Buckets {
+
+	b := &Buckets{
+		buckets: make(map[string]bucket.Bucket),
+		mu:      new(sync.Mutex),
+	}
+
+	return b
+}
+
+// Open returns the `bucket.Bucket` instance for 'uri', opening it if it has not already been opened.
+func (b *Buckets) Open(ctx context.Context, uri string) (bucket.Bucket, error) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	existing, ok := b.buckets[uri]
+
+	if ok {
+		return existing, nil
+	}
+
+	new_bucket, err := bucket.NewBucket(ctx, uri)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b.buckets[uri] = new_bucket
+	return new_bucket, nil
+}
+
+// Close closes all the buckets which have been opened.
+func (b *Buckets) Close() error {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	errs := make([]error, 0)
+
+	for uri, opened := range b.buckets {
+
+		err := opened.Close()
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Failed to close bucket %s, %w", uri, err))
+		}
+	}
+
+	b.buckets = make(map[string]bucket.Bucket)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,47 @@
+package picturebook
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestBuckets(t *testing.T) {
+
+	ctx := context.Background()
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to register buckets, %v", err)
+	}
+
+	buckets := NewBuckets()
+
+	uri := fmt.Sprintf("file://%s", t.TempDir())
+
+	first, err := buckets.Open(ctx, uri)
+
+	if err != nil {
+		t.Fatalf("Failed to open bucket, %v", err)
+	}
+
+	second, err := buckets.Open(ctx, uri)
+
+	if err != nil {
+		t.Fatalf("Failed to open bucket, %v", err)
+	}
+
+	if first != second {
+		t.Fatalf("Expected bucket to be reused")
+	}
+
+	err = buckets.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close buckets, %v", err)
+	}
+}
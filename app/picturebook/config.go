@@ -0,0 +1,130 @@
+package picturebook
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Config defines a struct containing default flag values and named profiles for the `picturebook` application.
+// Keys are flag names (without the leading dash), for example "margin-top" or "process", and values are the
+// flag values to assign. Flags that may be assigned more than once (for example "process") take a list of values.
+type Config struct {
+	// Flag values applied to every invocation of the `picturebook` application.
+	Defaults map[string]any `json:"defaults,omitempty" yaml:"defaults,omitempty" toml:"defaults,omitempty"`
+	// Named sets of flag values applied, after `Defaults`, when selected by the `-profile` flag.
+	Profiles map[string]map[string]any `json:"profiles,omitempty" yaml:"profiles,omitempty" toml:"profiles,omitempty"`
+}
+
+// LoadConfig returns a new `Config` instance derived from the file at 'path'. Files ending in ".toml" are decoded
+// as TOML, files ending in ".json" are decoded as JSON and files ending in ".yaml" or ".yml" are decoded as YAML.
+func LoadConfig(ctx context.Context, path string) (*Config, error) {
+
+	body, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s, %w", path, err)
+	}
+
+	var cfg *Config
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(body, &cfg)
+	case ".json":
+		err = json.Unmarshal(body, &cfg)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(body, &cfg)
+	default:
+		return nil, fmt.Errorf("Unsupported config file type '%s'", filepath.Ext(path))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode %s, %w", path, err)
+	}
+
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	return cfg, nil
+}
+
+// Apply assigns the flag values in the `Defaults` section of 'cfg', followed by those in the profile named 'profile',
+// to 'fs'. Flags that have already been set (for example on the command line) are not overridden. If 'profile' is not
+// empty it must match one of the profiles defined in 'cfg'.
+func (cfg *Config) Apply(ctx context.Context, fs *flag.FlagSet, profile string) error {
+
+	// Flags that have been set explicitly, before any config values were applied.
+
+	explicit := make(map[string]bool)
+
+	fs.Visit(func(fl *flag.Flag) {
+		explicit[fl.Name] = true
+	})
+
+	values := make(map[string]any)
+
+	for k, v := range cfg.Defaults {
+		values[k] = v
+	}
+
+	if profile != "" {
+
+		p, ok := cfg.Profiles[profile]
+
+		if !ok {
+			return fmt.Errorf("Unknown profile '%s'", profile)
+		}
+
+		for k, v := range p {
+			values[k] = v
+		}
+	}
+
+	for name, v := range values {
+
+		switch name {
+		case "config", "profile":
+			return fmt.Errorf("The -%s flag can not be assigned in a config file", name)
+		}
+
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("Unknown flag '%s'", name)
+		}
+
+		if explicit[name] {
+			continue
+		}
+
+		// Lists are assigned one value at a time so that multi-value flags (like -process)
+		// are populated with each item in the list.
+
+		var items []any
+
+		switch v := v.(type) {
+		case []any:
+			items = v
+		default:
+			items = []any{v}
+		}
+
+		for _, item := range items {
+
+			err := fs.Set(name, fmt.Sprintf("%v", item))
+
+			if err != nil {
+				return fmt.Errorf("Failed to assign value for -%s flag, %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package picturebook
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sfomuseum/go-flags/multi"
+)
+
+func TestConfig(t *testing.T) {
+
+	ctx := context.Background()
+
+	configs := map[string]string{
+		"picturebook.toml": `
+[defaults]
+dpi = 300
+
+[profiles.zine-a5]
+size = "a5"
+margin = 0.25
+process = ["halftone://", "rotate://"]
+`,
+		"picturebook.json": `{"defaults": {"dpi": 300}, "profiles": {"zine-a5": {"size": "a5", "margin": 0.25, "process": ["halftone://", "rotate://"]}}}`,
+		"picturebook.yaml": `
+defaults:
+  dpi: 300
+profiles:
+  zine-a5:
+    size: a5
+    margin: 0.25
+    process:
+      - halftone://
+      - rotate://
+`,
+	}
+
+	root := t.TempDir()
+
+	for fname, body := range configs {
+
+		path := filepath.Join(root, fname)
+
+		err := os.WriteFile(path, []byte(body), 0644)
+
+		if err != nil {
+			t.Fatalf("Failed to write %s, %v", path, err)
+		}
+
+		cfg, err := LoadConfig(ctx, path)
+
+		if err != nil {
+			t.Fatalf("Failed to load %s, %v", path, err)
+		}
+
+		var size string
+		var margin float64
+		var dpi float64
+		var processes multi.MultiString
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.StringVar(&size, "size", "letter", "")
+		fs.Float64Var(&margin, "margin", 0.0, "")
+		fs.Float64Var(&dpi, "dpi", 150, "")
+		fs.Var(&processes, "process", "")
+
+		err = fs.Parse([]string{"-size", "a4"})
+
+		if err != nil {
+			t.Fatalf("Failed to parse flags, %v", err)
+		}
+
+		err = cfg.Apply(ctx, fs, "zine-a5")
+
+		if err != nil {
+			t.Fatalf("Failed to apply %s, %v", path, err)
+		}
+
+		if size != "a4" {
+			t.Fatalf("Expected -size flag to override config (%s), but got '%s'", fname, size)
+		}
+
+		if margin != 0.25 || dpi != 300 {
+			t.Fatalf("Unexpected margin (%f) or dpi (%f) for %s", margin, dpi, fname)
+		}
+
+		if len(processes) != 2 || processes[0] != "halftone://" {
+			t.Fatalf("Unexpected processes for %s: %v", fname, processes)
+		}
+
+		err = cfg.Apply(ctx, fs, "missing")
+
+		if err == nil {
+			t.Fatalf("Expected unknown profile to fail for %s", fname)
+		}
+	}
+}
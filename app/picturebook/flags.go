@@ -6,9 +6,13 @@ import (
 	"fmt"
 	gosort "sort"
 	"strings"
+	"time"
 
+	pb "github.com/aaronland/go-picturebook"
 	"github.com/aaronland/go-picturebook/caption"
 	"github.com/aaronland/go-picturebook/filter"
+	"github.com/aaronland/go-picturebook/output"
+	"github.com/aaronland/go-picturebook/preview"
 	"github.com/aaronland/go-picturebook/process"
 	"github.com/aaronland/go-picturebook/sort"
 	"github.com/aaronland/go-picturebook/text"
@@ -65,6 +69,15 @@ var target_uri string
 // A valid aaronland/go-picturebook/bucket.Bucket URI for where temporary picturebook-related images will be written to and read from.
 var tmpfile_uri string
 
+// An optional aaronland/go-picturebook/bucket.Bucket URI for where processed images are cached between runs.
+var cache_uri string
+
+// An optional aaronland/go-picturebook/bucket.Bucket URI for where PNG previews of each page are written to.
+var preview_uri string
+
+// The number of dots per inch at which page previews are rendered.
+var preview_dpi float64
+
 // A boolean flag indicating that, when necessary, an image should be rotated 90 degrees to use the most available page space.
 var fill_page bool
 
@@ -80,9 +93,54 @@ var odd_only bool
 // Boolean flag to signal verbose logging during the creation of a picturebook.
 var verbose bool
 
+// The path to an optional JSON Lines file defining one picturebook job per line.
+var batch string
+
+// Boolean flag to signal that the picturebook should be recreated whenever images in its sources change.
+var watch bool
+
+// The format used to report the results of the inspect subcommand. Valid options are "table" and "json".
+var inspect_format string
+
+// The format used to report the results of the handlers subcommand. Valid options are "text" and "json".
+var handlers_format string
+
+// The interval at which sources are checked for changes in watch mode.
+var watch_interval time.Duration
+
 // Boolean flag to signal that all the steps to create a picturebook should be taken but without creating a final picturebook document.
 var debug bool
 
+// The format of an optional manifest file, describing each page in a picturebook, to write alongside the picturebook.
+var manifest string
+
+// The format of an optional summary, written to STDERR, of the counts and timings of creating a picturebook.
+var summary string
+
+// The policy for images which can not be added to a picturebook. Valid options are "skip" and "strict".
+var error_policy string
+
+// Boolean flag to signal that images should be checked for problems, like low resolution, as they are added to a picturebook.
+var preflight bool
+
+// Boolean flag to signal that a picturebook should not be created if any images fail a preflight check.
+var preflight_strict bool
+
+// The minimum effective resolution, in dots per inch, of images checked by preflight.
+var preflight_min_dpi float64
+
+// The maximum aspect ratio of images checked by preflight.
+var preflight_max_aspect_ratio float64
+
+// An optional `output.Output` URI used to write a picturebook in a format other than PDF.
+var output_uri string
+
+// The title of a picturebook.
+var title string
+
+// The path to an optional spec file, encoded as YAML or JSON, defining the pages of a picturebook.
+var spec_path string
+
 // Zero or more valid `caption.Caption` URIs.
 var caption_uris multi.MultiString
 
@@ -104,9 +162,18 @@ var ocra_font bool
 // The maximum number of pages a picturebook can have.
 var max_pages int
 
+// The maximum number of images to gather and pre-process concurrently.
+var workers int
+
 // A registered `aaronland/go-picturebook/progress.Monitor` URI used to signal picturebook creation progress.
 var progress_monitor_uri string
 
+// The path to an optional config file, encoded as TOML, JSON or YAML, defining default flag values and named profiles.
+var config_path string
+
+// The name of a profile, defined in the config file, whose flag values should be applied.
+var config_profile string
+
 // formatSchemes takes a list of (scheme) strings and ensure that they are lower-cased and have a trailing "://" string.
 func formatSchemes(schemes []string) []string {
 
@@ -150,11 +217,15 @@ func DefaultFlagSet(ctx context.Context) (*flag.FlagSet, error) {
 	available_sorters := sort.AvailableSorters()
 	available_sorters_str := formatSchemesAsString(available_sorters)
 
-	desc_filters := fmt.Sprintf("A valid filter.Filter URI. Valid schemes are: %s.", available_filters_str)
-	desc_captions := fmt.Sprintf("Zero or more valid caption.Caption URIs. Valid schemes are: %s.", available_captions_str)
-	desc_texts := fmt.Sprintf("A valid text.Text URI. Valid schemes are: %s.", available_texts_str)
-	desc_processes := fmt.Sprintf("A valid process.Process URI. Valid schemes are: %s.", available_processes_str)
-	desc_sorters := fmt.Sprintf("A valid sort.Sorter URI. Valid schemes are: %s.", available_sorters_str)
+	available_outputs := output.AvailableOutputs()
+	available_outputs_str := formatSchemesAsString(available_outputs)
+
+	desc_filters := fmt.Sprintf("A valid filter.Filter URI. Valid schemes are: %s. Run 'picturebook handlers filter' for a description of the parameters each scheme accepts.", available_filters_str)
+	desc_captions := fmt.Sprintf("Zero or more valid caption.Caption URIs. Valid schemes are: %s. Run 'picturebook handlers caption' for a description of the parameters each scheme accepts.", available_captions_str)
+	desc_texts := fmt.Sprintf("A valid text.Text URI. Valid schemes are: %s. Run 'picturebook handlers text' for a description of the parameters each scheme accepts.", available_texts_str)
+	desc_processes := fmt.Sprintf("A valid process.Process URI. Valid schemes are: %s. Run 'picturebook handlers process' for a description of the parameters each scheme accepts.", available_processes_str)
+	desc_sorters := fmt.Sprintf("A valid sort.Sorter URI. Valid schemes are: %s. Run 'picturebook handlers sort' for a description of the parameters each scheme accepts.", available_sorters_str)
+	desc_outputs := fmt.Sprintf("An optional output.Output URI used to write your picturebook in a format other than PDF. Valid schemes are: %s. Run 'picturebook handlers output' for a description of the parameters each scheme accepts. If the value of -filename ends in '.pdf' it will be replaced by the extension for the output. If empty a PDF document is written.", available_outputs_str)
 
 	desc_buckets := fmt.Sprintf("A valid GoCloud blob URI to specify where files should be read from. Available schemes are: %s. If no URI scheme is included then the file:// scheme is assumed.", available_buckets_str)
 
@@ -176,13 +247,30 @@ func DefaultFlagSet(ctx context.Context) (*flag.FlagSet, error) {
 
 	fs.BoolVar(&fill_page, "fill-page", false, "If necessary rotate image 90 degrees to use the most available page space. Note that any '-process' flags involving colour space manipulation will automatically be applied to images after they have been rotated.")
 
-	fs.StringVar(&filename, "filename", "picturebook.pdf", "The filename (path) for your picturebook.")
+	fs.StringVar(&filename, "filename", "picturebook.pdf", "The filename (path) for your picturebook. If \"-\" the picturebook is written to STDOUT; in that case the -output flag must be a single-file output and the -debug and -manifest flags can not be used.")
+	fs.StringVar(&title, "title", "", "The title of your picturebook. This is recorded in outputs, like EPUB, which store a title in the document itself. If empty the value of -filename, without its extension, is used.")
+	fs.StringVar(&output_uri, "output", "", desc_outputs)
 
 	fs.BoolVar(&verbose, "verbose", false, "Display verbose output as the picturebook is created.")
+	fs.StringVar(&batch, "batch", "", "The path to an optional JSON Lines file defining one picturebook per line. Each line is a JSON-encoded object with the same properties as the RunOptions struct (for example Sources, Filename or ProcessURIs) which are applied on top of the values of the flags passed on the command line. All the picturebooks are created in a single process and a summary is written to STDOUT when they have all been created. If -cache-uri is empty a temporary cache, shared by all the picturebooks, is used.")
+	fs.BoolVar(&watch, "watch", false, "Keep running after the picturebook has been created and recreate it whenever images in its sources (or its -spec file) are added, removed or modified. Sources are polled every -watch-interval and the picturebook is recreated once they have stopped changing. If -cache-uri is empty a temporary cache is used so that processed images are reused between builds.")
+	fs.DurationVar(&watch_interval, "watch-interval", 5*time.Second, "The interval at which sources are checked for changes when the -watch flag is set.")
+	fs.BoolVar(&debug, "debug", false, "Take all the steps to create a picturebook but without creating a final picturebook document. Instead a manifest of the pages that would have been added is written to the -target-uri bucket, using the value of -filename with a '.json' (or '.csv' if -manifest=csv) extension.")
+	fs.StringVar(&manifest, "manifest", "", "The format of an optional manifest file, describing each page in the picturebook, to write alongside the picturebook. The manifest is written to the -target-uri bucket using the value of -filename with the format as its extension. Valid options are: json, csv.")
+
+	fs.StringVar(&summary, "summary", "", "The format of an optional summary, written to STDERR once the picturebook has been created, of the number of files gathered, skipped, filtered (per filter), processed and captioned, the number of pages of each type, the size of the picturebook and the time spent in each stage of creating it. Valid options are: text, json.")
+	fs.StringVar(&error_policy, "error-policy", pb.ERROR_POLICY_SKIP, "The policy for images which can not be added to your picturebook, for example because they can not be decoded or a -filter, -caption, -text or -process flag failed. Valid options are: skip, strict. If \"skip\" those images are left out and a list of every file which was skipped, and why, is written to STDERR once the picturebook has been created. If \"strict\" the picturebook is not created. Files which are not images are always skipped.")
+
+	fs.BoolVar(&preflight, "preflight", false, "Check each image, as it is placed on a page, for problems which may not be visible on screen but will be in print: an effective resolution below -preflight-min-dpi, images which have been enlarged, aspect ratios above -preflight-max-aspect-ratio and CMYK or 16-bit source images. A report of any problems is written to STDERR before the picturebook is saved.")
+	fs.BoolVar(&preflight_strict, "preflight-strict", false, "Enable preflight checks (see -preflight) and do not create the picturebook if any image fails them.")
+	fs.Float64Var(&preflight_min_dpi, "preflight-min-dpi", pb.DEFAULT_PREFLIGHT_MIN_DPI, "The minimum effective resolution, in dots per inch, of an image at the size it is placed on a page. If 0 resolution is not checked.")
+	fs.Float64Var(&preflight_max_aspect_ratio, "preflight-max-aspect-ratio", pb.DEFAULT_PREFLIGHT_MAX_ASPECT_RATIO, "The maximum ratio of the longest side of an image to its shortest side. If 0 aspect ratios are not checked.")
 
 	fs.BoolVar(&even_only, "even-only", false, "Only include images on even-numbered pages.")
 	fs.BoolVar(&odd_only, "odd-only", false, "Only include images on odd-numbered pages.")
 
+	fs.StringVar(&spec_path, "spec", "", "The path to an optional spec file, encoded as YAML or JSON, defining global options and an ordered list of pages for your picturebook. If present images are not gathered from the sources passed to the application and any -filter and -sort flags are ignored. Relative image paths are resolved relative to the spec file when -source-uri is empty.")
+
 	fs.Var(&caption_uris, "caption", desc_captions)
 
 	fs.StringVar(&text_uri, "text", "", desc_texts)
@@ -203,8 +291,59 @@ func DefaultFlagSet(ctx context.Context) (*flag.FlagSet, error) {
 	desc_buckets_tmp := fmt.Sprintf("%s If empty the operating system's temporary directory will be used.", desc_buckets)
 	fs.StringVar(&tmpfile_uri, "tmpfile-uri", "", desc_buckets_tmp)
 
+	desc_buckets_cache := fmt.Sprintf("%s If not empty the results of applying any -process flags to an image will be cached here, keyed by the contents of the image and the -process flags, and reused in subsequent runs.", desc_buckets)
+	fs.StringVar(&cache_uri, "cache-uri", "", desc_buckets_cache)
+
+	desc_buckets_preview := fmt.Sprintf("%s If not empty a low-resolution PNG preview of each page, named page-{NUMBER}.png, and a contact sheet of all the pages, named contact-sheet.png, will be written here when the picturebook is saved.", desc_buckets)
+	fs.StringVar(&preview_uri, "preview-uri", "", desc_buckets_preview)
+	fs.Float64Var(&preview_dpi, "preview-dpi", preview.DEFAULT_DPI, "The DPI (dots per inch) resolution at which page previews are rendered.")
+
 	fs.IntVar(&max_pages, "max-pages", 0, "An optional value to indicate that a picturebook should not exceed this number of pages")
+	fs.IntVar(&workers, "workers", 1, "The maximum number of images to gather and pre-process (filter, caption, text and process) concurrently.")
 
 	fs.StringVar(&progress_monitor_uri, "progress-monitor-uri", "progressbar://", "A registered aaronland/go-picturebook/progress.Monitor URI")
+
+	fs.StringVar(&config_path, "config", "", "The path to an optional config file, encoded as TOML, JSON or YAML, defining default flag values and named profiles. Flags passed on the command line override values in the config file.")
+	fs.StringVar(&config_profile, "profile", "", "The name of a profile, defined in the file specified by the -config flag, whose flag values should be applied.")
+	return fs, nil
+}
+
+// InspectFlagSet returns a `flag.FlagSet` with required flags and default values for the `inspect` subcommand. These are
+// the same flags returned by `DefaultFlagSet` with the addition of a -format flag.
+func InspectFlagSet(ctx context.Context) (*flag.FlagSet, error) {
+
+	fs, err := DefaultFlagSet(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fs.StringVar(&inspect_format, "format", INSPECT_FORMAT_TABLE, fmt.Sprintf("The format used to report the results of inspecting each file. Valid options are: %s, %s.", INSPECT_FORMAT_TABLE, INSPECT_FORMAT_JSON))
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Report how each file in one or more sources would be treated by picturebook, without creating a picturebook.\n\n")
+		fmt.Fprintf(fs.Output(), "Usage:\n\t picturebook inspect [options] path(N) path(N)\n\n")
+		fmt.Fprintf(fs.Output(), "Valid options are:\n")
+		fs.PrintDefaults()
+	}
+
+	return fs, nil
+}
+
+// HandlersFlagSet returns a `flag.FlagSet` with required flags and default values for the `handlers` subcommand.
+func HandlersFlagSet(ctx context.Context) (*flag.FlagSet, error) {
+
+	fs := flagset.NewFlagSet("handlers")
+
+	fs.StringVar(&handlers_format, "format", HANDLERS_FORMAT_TEXT, fmt.Sprintf("The format used to report the description of each handler. Valid options are: %s, %s.", HANDLERS_FORMAT_TEXT, HANDLERS_FORMAT_JSON))
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "List the schemes, and their parameters, of the handlers registered with picturebook.\n\n")
+		fmt.Fprintf(fs.Output(), "Usage:\n\t picturebook handlers [options] [kind(N) kind(N)]\n\n")
+		fmt.Fprintf(fs.Output(), "Where kind is one of: %s. If no kinds are specified all the handlers are listed.\n\n", strings.Join(handler_kinds, ", "))
+		fmt.Fprintf(fs.Output(), "Valid options are:\n")
+		fs.PrintDefaults()
+	}
+
 	return fs, nil
 }
@@ -0,0 +1,237 @@
+package picturebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aaronland/go-picturebook/caption"
+	"github.com/aaronland/go-picturebook/filter"
+	"github.com/aaronland/go-picturebook/handler"
+	"github.com/aaronland/go-picturebook/output"
+	"github.com/aaronland/go-picturebook/process"
+	"github.com/aaronland/go-picturebook/progress"
+	"github.com/aaronland/go-picturebook/sort"
+	"github.com/aaronland/go-picturebook/text"
+)
+
+// HANDLERS_SUBCOMMAND is the name of the subcommand used to list the registered handlers and their parameters.
+const HANDLERS_SUBCOMMAND string = "handlers"
+
+// HANDLERS_FORMAT_TEXT signals that the results of the handlers subcommand should be reported as plain text.
+const HANDLERS_FORMAT_TEXT string = "text"
+
+// HANDLERS_FORMAT_JSON signals that the results of the handlers subcommand should be reported as JSON.
+const HANDLERS_FORMAT_JSON string = "json"
+
+// handler_kinds is the list of handler kinds, in the order they are reported by the handlers subcommand.
+var handler_kinds = []string{
+	handler.CAPTION,
+	handler.FILTER,
+	handler.OUTPUT,
+	handler.PROCESS,
+	handler.PROGRESS,
+	handler.SORT,
+	handler.TEXT,
+}
+
+// handler_flags is a map of handler kinds to the flag used to specify them.
+var handler_flags = map[string]string{
+	handler.CAPTION:  "-caption",
+	handler.FILTER:   "-filter",
+	handler.OUTPUT:   "-output",
+	handler.PROCESS:  "-process",
+	handler.PROGRESS: "-progress-monitor-uri",
+	handler.SORT:     "-sort",
+	handler.TEXT:     "-text",
+}
+
+// type HandlerKind defines a struct containing the descriptions of every registered handler of a given kind.
+type HandlerKind struct {
+	// The kind of handler, for example "caption" or "process".
+	Kind string `json:"kind"`
+	// The flag used to specify handlers of this kind.
+	Flag string `json:"flag"`
+	// The descriptions of each registered handler. Handlers which have not registered a description only define a `Scheme` property.
+	Handlers []*handler.Description `json:"handlers"`
+}
+
+// RunHandlers will run the `handlers` subcommand configured using 'args', which are the command line arguments following
+// the name of the subcommand. It writes the description of every registered handler, or only those handlers whose kinds
+// are passed as positional arguments, to STDOUT.
+func RunHandlers(ctx context.Context, args []string) error {
+
+	fs, err := HandlersFlagSet(ctx)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create handlers flag set, %w", err)
+	}
+
+	err = fs.Parse(args)
+
+	if err != nil {
+		return fmt.Errorf("Failed to parse flags, %w", err)
+	}
+
+	return runHandlers(ctx, fs.Args(), handlers_format, os.Stdout)
+}
+
+// runHandlers writes the descriptions of the handlers of each kind in 'kinds', or all kinds if empty, to 'wr' encoded as 'format'.
+func runHandlers(ctx context.Context, kinds []string, format string, wr io.Writer) error {
+
+	switch format {
+	case HANDLERS_FORMAT_TEXT, HANDLERS_FORMAT_JSON:
+		// pass
+	default:
+		return fmt.Errorf("Invalid or unsupported format '%s'", format)
+	}
+
+	if len(kinds) == 0 {
+		kinds = handler_kinds
+	}
+
+	results := make([]*HandlerKind, len(kinds))
+
+	for idx, kind := range kinds {
+
+		kind = strings.ToLower(kind)
+
+		if !slices.Contains(handler_kinds, kind) {
+			return fmt.Errorf("Invalid handler kind '%s'. Valid kinds are: %s", kind, strings.Join(handler_kinds, ", "))
+		}
+
+		results[idx] = handlerKind(kind)
+	}
+
+	if format == HANDLERS_FORMAT_JSON {
+
+		enc := json.NewEncoder(wr)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+
+		return enc.Encode(results)
+	}
+
+	for idx, k := range results {
+
+		if idx > 0 {
+			fmt.Fprintln(wr, "")
+		}
+
+		writeHandlerKind(wr, k)
+	}
+
+	return nil
+}
+
+// handlerKind returns a `HandlerKind` instance containing the descriptions of every registered handler of kind 'kind'.
+func handlerKind(kind string) *HandlerKind {
+
+	var schemes []string
+
+	switch kind {
+	case handler.CAPTION:
+		schemes = caption.AvailableCaptions()
+	case handler.FILTER:
+		schemes = filter.AvailableFilters()
+	case handler.OUTPUT:
+		schemes = output.AvailableOutputs()
+	case handler.PROCESS:
+		schemes = process.AvailableProcesses()
+	case handler.PROGRESS:
+		schemes = progress.AvailableMonitors()
+	case handler.SORT:
+		schemes = sort.AvailableSorters()
+	case handler.TEXT:
+		schemes = text.AvailableTexts()
+	}
+
+	slices.Sort(schemes)
+
+	k := &HandlerKind{
+		Kind:     kind,
+		Flag:     handler_flags[kind],
+		Handlers: make([]*handler.Description, len(schemes)),
+	}
+
+	for idx, scheme := range schemes {
+
+		desc, exists := handler.GetDescription(kind, scheme)
+
+		if !exists {
+			desc = &handler.Description{
+				Scheme: strings.ToLower(scheme),
+			}
+		}
+
+		k.Handlers[idx] = desc
+	}
+
+	return k
+}
+
+// writeHandlerKind writes the descriptions in 'k' to 'wr' as plain text.
+func writeHandlerKind(wr io.Writer, k *HandlerKind) {
+
+	fmt.Fprintf(wr, "%s handlers (%s)\n", k.Kind, k.Flag)
+
+	for _, desc := range k.Handlers {
+
+		syntax := desc.Syntax
+
+		if syntax == "" {
+			syntax = fmt.Sprintf("%s://", desc.Scheme)
+		}
+
+		fmt.Fprintf(wr, "\n  %s\n", syntax)
+
+		if desc.Summary == "" {
+			fmt.Fprintf(wr, "    No description available.\n")
+			continue
+		}
+
+		fmt.Fprintf(wr, "    %s\n", desc.Summary)
+
+		if len(desc.Parameters) > 0 {
+
+			fmt.Fprintf(wr, "\n    Parameters:\n")
+
+			for _, p := range desc.Parameters {
+
+				details := []string{
+					p.Location,
+					p.Type,
+				}
+
+				if p.Required {
+					details = append(details, "required")
+				}
+
+				if p.Default != "" {
+					details = append(details, fmt.Sprintf("default %s", p.Default))
+				}
+
+				fmt.Fprintf(wr, "      %s (%s) %s", p.Name, strings.Join(details, ", "), p.Description)
+
+				if len(p.Values) > 0 {
+					fmt.Fprintf(wr, " Valid values are: %s.", strings.Join(p.Values, ", "))
+				}
+
+				fmt.Fprintln(wr, "")
+			}
+		}
+
+		if len(desc.Examples) > 0 {
+
+			fmt.Fprintf(wr, "\n    Examples:\n")
+
+			for _, ex := range desc.Examples {
+				fmt.Fprintf(wr, "      %s\n", ex)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package picturebook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestHandlerDescriptions(t *testing.T) {
+
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+
+	err := runHandlers(ctx, nil, HANDLERS_FORMAT_JSON, &buf)
+
+	if err != nil {
+		t.Fatalf("Failed to list handlers, %v", err)
+	}
+
+	var results []*HandlerKind
+
+	err = json.Unmarshal(buf.Bytes(), &results)
+
+	if err != nil {
+		t.Fatalf("Failed to decode handlers, %v", err)
+	}
+
+	if len(results) != len(handler_kinds) {
+		t.Fatalf("Unexpected number of handler kinds: %d", len(results))
+	}
+
+	// Every handler that ships with picturebook should describe itself
+
+	for _, k := range results {
+
+		if len(k.Handlers) == 0 {
+			t.Fatalf("No %s handlers listed", k.Kind)
+		}
+
+		for _, desc := range k.Handlers {
+
+			if desc.Summary == "" || desc.Syntax == "" || len(desc.Examples) == 0 {
+				t.Fatalf("Missing description for %s %s:// handler", k.Kind, desc.Scheme)
+			}
+		}
+	}
+
+	err = runHandlers(ctx, []string{"bogus"}, HANDLERS_FORMAT_TEXT, &buf)
+
+	if err == nil {
+		t.Fatalf("Expected invalid handler kind to be rejected")
+	}
+}
@@ -0,0 +1,211 @@
+package picturebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/aaronland/go-picturebook"
+	"github.com/aaronland/go-picturebook/bucket"
+)
+
+// INSPECT_SUBCOMMAND is the name of the subcommand used to inspect the files in a picturebook's sources.
+const INSPECT_SUBCOMMAND string = "inspect"
+
+// INSPECT_FORMAT_TABLE signals that the results of the inspect subcommand should be reported as a plain text table.
+const INSPECT_FORMAT_TABLE string = "table"
+
+// INSPECT_FORMAT_JSON signals that the results of the inspect subcommand should be reported as a JSON-encoded list.
+const INSPECT_FORMAT_JSON string = "json"
+
+// inspect_text_length is the maximum number of characters of a caption or text body displayed in a table.
+const inspect_text_length int = 40
+
+// RunInspect will run the `inspect` subcommand configured using 'args', which are the command line arguments following
+// the name of the subcommand. It gathers the files in the sources defined by 'args', without processing them or creating
+// a picturebook, and reports how each one would be treated to STDOUT.
+func RunInspect(ctx context.Context, args []string) error {
+
+	fs, err := InspectFlagSet(ctx)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create inspect flag set, %w", err)
+	}
+
+	err = fs.Parse(args)
+
+	if err != nil {
+		return fmt.Errorf("Failed to parse flags, %w", err)
+	}
+
+	app_opts, err := runOptionsFromParsedFlagSet(ctx, fs)
+
+	if err != nil {
+		return err
+	}
+
+	return runInspect(ctx, app_opts, inspect_format, os.Stdout)
+}
+
+// runInspect inspects the files in the sources defined by 'app_opts' and writes the results to 'wr' encoded as 'format'.
+func runInspect(ctx context.Context, app_opts *RunOptions, format string, wr io.Writer) error {
+
+	switch format {
+	case INSPECT_FORMAT_TABLE, INSPECT_FORMAT_JSON:
+		// pass
+	default:
+		return fmt.Errorf("Invalid or unsupported format '%s'", format)
+	}
+
+	if app_opts.Spec != "" {
+		return fmt.Errorf("The inspect subcommand does not support the -spec flag")
+	}
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		return fmt.Errorf("Failed to register gocloud buckets, %w", err)
+	}
+
+	source_uri := app_opts.SourceBucketURI
+
+	if source_uri == "" {
+		source_uri = "file:///"
+	}
+
+	source_uri, err = ensureScheme(source_uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to ensure scheme for source URI %s, %w", source_uri, err)
+	}
+
+	paths := app_opts.Sources
+
+	// This mirrors what RunWithOptions does when no sources are defined
+
+	if len(paths) == 0 {
+		paths = []string{filepath.Base(source_uri)}
+		source_uri = filepath.Dir(source_uri)
+	}
+
+	source_bucket, err := bucket.NewBucket(ctx, source_uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to open source bucket, %w", err)
+	}
+
+	defer source_bucket.Close()
+
+	pb_opts, err := newPictureBookOptions(ctx, app_opts)
+
+	if err != nil {
+		return err
+	}
+
+	pb_opts.Source = source_bucket
+
+	book, err := pb.NewPictureBook(ctx, pb_opts)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create new picturebook, %w", err)
+	}
+
+	filter_uris := make([]string, len(app_opts.FilterURIs))
+
+	for idx, filter_uri := range app_opts.FilterURIs {
+		filter_uris[idx] = ensureHandlerScheme(filter_uri)
+	}
+
+	inspect_opts := &pb.InspectOptions{
+		FilterURIs: filter_uris,
+	}
+
+	inspections, err := book.Inspect(ctx, paths, inspect_opts)
+
+	if err != nil {
+		return fmt.Errorf("Failed to inspect pictures, %w", err)
+	}
+
+	if format == INSPECT_FORMAT_JSON {
+
+		enc := json.NewEncoder(wr)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(inspections)
+	}
+
+	return writeInspectTable(wr, inspections)
+}
+
+// writeInspectTable writes 'inspections' to 'wr' as a plain text table.
+func writeInspectTable(wr io.Writer, inspections []*pb.PictureInspection) error {
+
+	tw := tabwriter.NewWriter(wr, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "PATH\tMIMETYPE\tPIXELS\tDPI\tDATETIME\tCAPTION\tTEXT\tSTATUS\t")
+
+	included := 0
+
+	for _, i := range inspections {
+
+		pixels := ""
+		dpi := ""
+		dt := ""
+
+		if i.Width > 0 && i.Height > 0 {
+			pixels = fmt.Sprintf("%dx%d", i.Width, i.Height)
+		}
+
+		if i.DPI > 0 {
+			dpi = fmt.Sprintf("%.0f", i.DPI)
+		}
+
+		if i.DateTime != nil {
+			dt = i.DateTime.Format(time.DateTime)
+		}
+
+		var status string
+
+		switch {
+		case i.Included:
+			status = "included"
+			included += 1
+		case i.RejectedBy != "":
+			status = fmt.Sprintf("rejected by %s", i.RejectedBy)
+		case i.Error != "":
+			status = fmt.Sprintf("skipped: %s", i.Error)
+		default:
+			status = "rejected by filter"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", i.Path, i.MimeType, pixels, dpi, dt, truncateText(i.Caption), truncateText(i.Text), status)
+	}
+
+	err := tw.Flush()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(wr, "\n%d of %d files would be included\n", included, len(inspections))
+	return err
+}
+
+// truncateText returns the first line of 'txt' shortened to at most `inspect_text_length` characters.
+func truncateText(txt string) string {
+
+	txt, _, _ = strings.Cut(txt, "\n")
+	runes := []rune(txt)
+
+	if len(runes) > inspect_text_length {
+		return string(runes[:inspect_text_length-1]) + "…"
+	}
+
+	return txt
+}
@@ -0,0 +1,129 @@
+package picturebook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/aaronland/go-picturebook"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestRunInspect(t *testing.T) {
+
+	ctx := context.Background()
+
+	root := t.TempDir()
+
+	im := image.NewNRGBA(image.Rect(0, 0, 300, 150))
+	im.Set(10, 10, color.White)
+
+	for _, fname := range []string{"keep.png", "skip.png"} {
+
+		wr, err := os.Create(filepath.Join(root, fname))
+
+		if err != nil {
+			t.Fatalf("Failed to create %s, %v", fname, err)
+		}
+
+		err = png.Encode(wr, im)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s, %v", fname, err)
+		}
+
+		err = wr.Close()
+
+		if err != nil {
+			t.Fatalf("Failed to close %s, %v", fname, err)
+		}
+	}
+
+	err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("notes"), 0644)
+
+	if err != nil {
+		t.Fatalf("Failed to write notes.txt, %v", err)
+	}
+
+	app_opts, err := RunOptionsFromArgs(ctx, []string{
+		"-filter", "regexp://exclude?pattern=skip",
+		"-caption", "filename",
+		root,
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to derive options, %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	err = runInspect(ctx, app_opts, INSPECT_FORMAT_JSON, &buf)
+
+	if err != nil {
+		t.Fatalf("Failed to inspect sources, %v", err)
+	}
+
+	var inspections []*pb.PictureInspection
+
+	err = json.Unmarshal(buf.Bytes(), &inspections)
+
+	if err != nil {
+		t.Fatalf("Failed to decode inspections, %v", err)
+	}
+
+	if len(inspections) != 3 {
+		t.Fatalf("Unexpected number of inspections: %d", len(inspections))
+	}
+
+	results := make(map[string]*pb.PictureInspection)
+
+	for _, i := range inspections {
+		results[filepath.Base(i.Path)] = i
+	}
+
+	keep := results["keep.png"]
+
+	if keep == nil || !keep.Included || keep.Caption != "keep.png" || keep.Width != 300 || keep.Height != 150 {
+		t.Fatalf("Unexpected inspection for keep.png: %+v", keep)
+	}
+
+	// The image fits on the page so it is placed at its original size, which is to say at the picturebook's DPI
+
+	if keep.DPI != app_opts.DPI {
+		t.Fatalf("Unexpected DPI for keep.png: %f", keep.DPI)
+	}
+
+	skip := results["skip.png"]
+
+	if skip == nil || skip.Included || skip.RejectedBy != "regexp://exclude?pattern=skip" {
+		t.Fatalf("Unexpected inspection for skip.png: %+v", skip)
+	}
+
+	if len(skip.Filters) != 1 || skip.Filters[0].Passed {
+		t.Fatalf("Unexpected filter results for skip.png: %+v", skip.Filters)
+	}
+
+	notes := results["notes.txt"]
+
+	if notes == nil || notes.Included || notes.Error == "" {
+		t.Fatalf("Unexpected inspection for notes.txt: %+v", notes)
+	}
+
+	buf.Reset()
+
+	err = runInspect(ctx, app_opts, INSPECT_FORMAT_TABLE, &buf)
+
+	if err != nil {
+		t.Fatalf("Failed to inspect sources as a table, %v", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte("1 of 3 files would be included")) {
+		t.Fatalf("Unexpected table: %s", buf.String())
+	}
+}
@@ -5,7 +5,14 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/output"
+	"github.com/aaronland/go-picturebook/progress"
 	"github.com/sfomuseum/go-flags/flagset"
 )
 
@@ -17,6 +24,12 @@ type RunOptions struct {
 	TargetBucketURI string
 	// A valid aaronland/go-picturebook/bucket.Bucket URI for where temporary picturebook-related images will be written to and read from.
 	TempBucketURI string
+	// An optional aaronland/go-picturebook/bucket.Bucket URI for where processed images are cached between runs.
+	CacheBucketURI string
+	// An optional aaronland/go-picturebook/bucket.Bucket URI for where PNG previews of each page are written to.
+	PreviewBucketURI string
+	// The number of dots per inch at which page previews are rendered.
+	PreviewDPI float64
 	// String label defining the orientation of picturebook PDF files. Valid orientations are: 'P' and 'L' for portrait and landscape mode respectively.
 	Orientation string
 	// A common paper size to use for the size of your picturebook. Valid sizes are: "a3", "a4", "a5", "letter", "legal", or "tabloid".
@@ -43,6 +56,8 @@ type RunOptions struct {
 	OddOnly bool
 	// The maximum number of pages a picturebook can have.
 	MaxPages int
+	// The maximum number of images to gather and pre-process concurrently.
+	Workers int
 	// The size of the top margin for a picturebook.
 	MarginTop float64
 	// The size of the bottom margin for a picturebook.
@@ -63,18 +78,91 @@ type RunOptions struct {
 	SortURI string
 	// One or more paths to crawl for images to add to a picturebook.
 	Sources []string
+	// The path to an optional spec file, encoded as YAML or JSON, defining the pages of a picturebook. If present `Sources`, `FilterURIs` and `SortURI` are ignored.
+	Spec string
 	// The base filename of the finished picturebook document.
 	Filename string
+	// The title of the finished picturebook document.
+	Title string
+	// An optional `output.Output` URI used to write the picturebook in a format other than PDF.
+	OutputURI string
+	// The format of an optional manifest file, describing each page in a picturebook, to write alongside the picturebook. Valid options are "json" and "csv".
+	Manifest string
+	// The format of an optional summary, written to STDERR, of the counts and timings of creating a picturebook. Valid options are "text" and "json".
+	Summary string
+	// The policy for images which can not be added to a picturebook. Valid options are "skip" and "strict".
+	ErrorPolicy string
+	// Boolean flag to signal that images should be checked for problems, like low resolution, as they are added to a picturebook.
+	Preflight bool
+	// Boolean flag to signal that a picturebook should not be created if any images fail a preflight check. This implies `Preflight`.
+	PreflightStrict bool
+	// The minimum effective resolution, in dots per inch, of images checked by preflight. If 0 resolution is not checked.
+	PreflightMinDPI float64
+	// The maximum ratio of the longest side of an image to its shortest side checked by preflight. If 0 aspect ratios are not checked.
+	PreflightMaxAspectRatio float64
 	// A registered `aaronland/go-picturebook/progress.Monitor` URI used to signal picturebook creation progress.
 	ProgressMonitorURI string
 	// Boolean flag to signal verbose logging during the creation of a picturebook.
 	Verbose bool
+	// Boolean flag to signal that all the steps to create a picturebook should be taken but without creating a final picturebook document.
+	// Instead a JSON manifest of the pages that would have been added is written.
+	Debug bool
+	// The path to an optional JSON Lines file defining one picturebook job, encoded as a `RunOptions` object, per line.
+	Batch string
+	// Boolean flag to signal that the picturebook should be recreated whenever images in its sources change.
+	Watch bool
+	// The interval at which sources are checked for changes when `Watch` is true.
+	WatchInterval time.Duration
+	// An optional `aaronland/go-picturebook/progress.Monitor` instance used to signal picturebook creation progress. If present `ProgressMonitorURI` is ignored.
+	Monitor progress.Monitor `json:"-"`
 }
 
 // Derive a new `RunOptions` instances from 'fs'.
 func RunOptionsFromFlagSet(ctx context.Context, fs *flag.FlagSet) (*RunOptions, error) {
 
 	flagset.Parse(fs)
+	return runOptionsFromParsedFlagSet(ctx, fs)
+}
+
+// RunOptionsFromArgs derives a new `RunOptions` instance from the default flagset parsed using 'args' rather than the
+// command line. This is useful for deriving default options, including any config file and profile, in other applications.
+func RunOptionsFromArgs(ctx context.Context, args []string) (*RunOptions, error) {
+
+	fs, err := DefaultFlagSet(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create default flag set, %w", err)
+	}
+
+	err = fs.Parse(args)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse flags, %w", err)
+	}
+
+	return runOptionsFromParsedFlagSet(ctx, fs)
+}
+
+// runOptionsFromParsedFlagSet derives a new `RunOptions` instance from 'fs' which is expected to have been parsed already.
+func runOptionsFromParsedFlagSet(ctx context.Context, fs *flag.FlagSet) (*RunOptions, error) {
+
+	if config_path != "" {
+
+		cfg, err := LoadConfig(ctx, config_path)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load config, %w", err)
+		}
+
+		err = cfg.Apply(ctx, fs, config_profile)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to apply config, %w", err)
+		}
+
+	} else if config_profile != "" {
+		return nil, fmt.Errorf("The -profile flag requires the -config flag")
+	}
 
 	if tmpfile_uri == "" {
 
@@ -107,6 +195,10 @@ func RunOptionsFromFlagSet(ctx context.Context, fs *flag.FlagSet) (*RunOptions,
 		SourceBucketURI: source_uri,
 		TargetBucketURI: target_uri,
 		TempBucketURI:   tmpfile_uri,
+		CacheBucketURI:  cache_uri,
+
+		PreviewBucketURI: preview_uri,
+		PreviewDPI:       preview_dpi,
 
 		Orientation: orientation,
 		Size:        size,
@@ -124,6 +216,8 @@ func RunOptionsFromFlagSet(ctx context.Context, fs *flag.FlagSet) (*RunOptions,
 		Bleed:    bleed,
 		FillPage: fill_page,
 
+		Workers: workers,
+
 		EvenOnly:    even_only,
 		OddOnly:     odd_only,
 		OCRAFont:    ocra_font,
@@ -133,11 +227,116 @@ func RunOptionsFromFlagSet(ctx context.Context, fs *flag.FlagSet) (*RunOptions,
 		TextURI:     text_uri,
 		SortURI:     sort_uri,
 
-		Sources:            fs.Args(),
-		Filename:           filename,
-		ProgressMonitorURI: progress_monitor_uri,
-		Verbose:            verbose,
+		Sources:                 fs.Args(),
+		Spec:                    spec_path,
+		Filename:                filename,
+		Title:                   title,
+		OutputURI:               output_uri,
+		Manifest:                manifest,
+		Summary:                 summary,
+		ErrorPolicy:             error_policy,
+		Preflight:               preflight,
+		PreflightStrict:         preflight_strict,
+		PreflightMinDPI:         preflight_min_dpi,
+		PreflightMaxAspectRatio: preflight_max_aspect_ratio,
+		ProgressMonitorURI:      progress_monitor_uri,
+		Verbose:                 verbose,
+		Debug:                   debug,
+		Batch:                   batch,
+		Watch:                   watch,
+		WatchInterval:           watch_interval,
 	}
 
 	return opts, nil
 }
+
+// Clone returns a copy of 'opts' which can be modified without affecting 'opts'.
+func (opts *RunOptions) Clone() *RunOptions {
+
+	c := *opts
+
+	c.FilterURIs = slices.Clone(opts.FilterURIs)
+	c.ProcessURIs = slices.Clone(opts.ProcessURIs)
+	c.CaptionURIs = slices.Clone(opts.CaptionURIs)
+	c.Sources = slices.Clone(opts.Sources)
+
+	return &c
+}
+
+// DocumentFilename returns the filename the picturebook described by 'opts' is written to, in the target bucket. This is the
+// value of `Filename` unless `OutputURI` is set and `Filename` ends in ".pdf", in which case the output's extension is used instead.
+func (opts *RunOptions) DocumentFilename(ctx context.Context) (string, error) {
+
+	if opts.OutputURI == "" {
+		return opts.Filename, nil
+	}
+
+	o, err := output.NewOutput(ctx, opts.OutputURI)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to create new output, %w", err)
+	}
+
+	return documentFilename(opts.Filename, o), nil
+}
+
+// NewTargetBucket returns a new `bucket.Bucket` instance for the `TargetBucketURI` property of 'opts'. If empty the current
+// working directory is used.
+func (opts *RunOptions) NewTargetBucket(ctx context.Context) (bucket.Bucket, error) {
+
+	target_uri, err := opts.targetBucketURI()
+
+	if err != nil {
+		return nil, err
+	}
+
+	target_bucket, err := bucket.NewBucket(ctx, target_uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open target bucket, %w", err)
+	}
+
+	return target_bucket, nil
+}
+
+// targetBucketURI returns the URI of the target bucket for the `TargetBucketURI` property of 'opts'. If empty the current
+// working directory is used.
+func (opts *RunOptions) targetBucketURI() (string, error) {
+
+	target_uri := opts.TargetBucketURI
+
+	if target_uri == "" {
+
+		cwd, err := os.Getwd()
+
+		if err != nil {
+			return "", fmt.Errorf("Failed to determine current working directory, %w", err)
+		}
+
+		target_uri = cwd
+	}
+
+	target_uri, err := ensureScheme(target_uri)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to ensure scheme for target URI %s, %w", target_uri, err)
+	}
+
+	target_uri, err = ensureSkipMetadata(target_uri)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to ensure ?metadata=skip for target URI %s, %w", target_uri, err)
+	}
+
+	return target_uri, nil
+}
+
+// documentFilename returns 'filename' with its ".pdf" extension, if present, replaced by the extension for 'o'.
+func documentFilename(filename string, o output.Output) string {
+
+	if filename == STDOUT || strings.ToLower(filepath.Ext(filename)) != ".pdf" {
+		return filename
+	}
+
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + o.Extension()
+}
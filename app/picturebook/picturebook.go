@@ -16,12 +16,17 @@ import (
 	"github.com/aaronland/go-picturebook/bucket"
 	"github.com/aaronland/go-picturebook/caption"
 	"github.com/aaronland/go-picturebook/filter"
+	"github.com/aaronland/go-picturebook/output"
 	"github.com/aaronland/go-picturebook/process"
 	"github.com/aaronland/go-picturebook/progress"
 	"github.com/aaronland/go-picturebook/sort"
+	"github.com/aaronland/go-picturebook/spec"
 	"github.com/aaronland/go-picturebook/text"
 )
 
+// STDOUT is the value of the -filename flag used to signal that the picturebook should be written to STDOUT.
+const STDOUT string = "-"
+
 // Regular expression for validating filter and caption URIs.
 var uri_re *regexp.Regexp
 
@@ -56,11 +61,6 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 // Run will run the `picturebook` application configured using 'app_opts'.
 func RunWithOptions(ctx context.Context, app_opts *RunOptions) error {
 
-	if app_opts.Verbose {
-		slog.SetLogLoggerLevel(slog.LevelDebug)
-		slog.Debug("Verbose logging enabled")
-	}
-
 	// START OF unfortunate bit of hoop-jumping to (re) register gocloud stuff
 	// because of the way Go imports are ordered.
 
@@ -72,37 +72,85 @@ func RunWithOptions(ctx context.Context, app_opts *RunOptions) error {
 
 	// END OF unfortunate bit of hoop-jumping to (re) register gocloud stuff
 
-	source_uri := app_opts.SourceBucketURI
-	target_uri := app_opts.TargetBucketURI
-	tmpfile_uri := app_opts.TempBucketURI
+	if app_opts.Batch != "" {
+		return runBatch(ctx, app_opts)
+	}
 
-	source_uri, err = ensureScheme(source_uri)
+	if app_opts.Watch {
+		return runWatch(ctx, app_opts)
+	}
 
-	if err != nil {
-		return fmt.Errorf("Failed to ensure scheme for source URI %s, %w", source_uri, err)
+	buckets := NewBuckets()
+	defer buckets.Close()
+
+	return RunWithBuckets(ctx, app_opts, buckets)
+}
+
+// RunWithBuckets will create the picturebook configured using 'app_opts' reading from, and writing to, buckets opened (or
+// reused) using 'buckets'. Buckets are not closed so that they can be shared by more than one picturebook. It is expected
+// that the gocloud.dev/blob schemes have already been registered (see `bucket.RegisterGoCloudBuckets`) and that 'app_opts'
+// does not define a batch file or watch mode.
+func RunWithBuckets(ctx context.Context, app_opts *RunOptions, buckets *Buckets) (run_err error) {
+
+	if app_opts.Verbose {
+		slog.SetLogLoggerLevel(slog.LevelDebug)
+		slog.Debug("Verbose logging enabled")
 	}
 
-	if target_uri == "" {
+	monitor := app_opts.Monitor
+
+	if monitor == nil {
 
-		cwd, err := os.Getwd()
+		m, err := progress.NewMonitor(ctx, app_opts.ProgressMonitorURI)
 
 		if err != nil {
-			return fmt.Errorf("Failed to determine current working directory, %w", err)
+			return fmt.Errorf("Failed to create new progress monitor, %w", err)
 		}
 
-		target_uri = cwd
+		// Report the outcome of creating the picturebook, for monitors which implement the progress.Finisher interface,
+		// before closing the monitor. The monitor is created before anything else so that errors setting up the picturebook
+		// (opening buckets, reading the spec file and so on) are reported too
+
+		defer func() {
+
+			err := progress.Finish(ctx, m, run_err)
+
+			if err != nil {
+				slog.Warn("Failed to report outcome to progress monitor", "error", err)
+			}
+
+			m.Close()
+		}()
+
+		monitor = m
 	}
 
-	target_uri, err = ensureScheme(target_uri)
+	source_uri := app_opts.SourceBucketURI
+	tmpfile_uri := app_opts.TempBucketURI
 
-	if err != nil {
-		return fmt.Errorf("Failed to ensure scheme for target URI %s, %w", target_uri, err)
+	var pb_spec *spec.Spec
+
+	if app_opts.Spec != "" {
+
+		s, spec_source_uri, err := loadSpec(ctx, app_opts)
+
+		if err != nil {
+			return err
+		}
+
+		source_uri = spec_source_uri
+		pb_spec = s
 	}
 
-	target_uri, err = ensureSkipMetadata(target_uri)
+	source_uri, err := ensureScheme(source_uri)
 
 	if err != nil {
-		return fmt.Errorf("Failed to ensure ?metadata=skip for target URI %s, %w", target_uri, err)
+		return fmt.Errorf("Failed to ensure scheme for source URI %s, %w", source_uri, err)
+	}
+
+	if len(app_opts.Sources) == 0 && pb_spec == nil {
+		app_opts.Sources = []string{filepath.Base(source_uri)}
+		source_uri = filepath.Dir(source_uri)
 	}
 
 	tmpfile_uri, err = ensureScheme(tmpfile_uri)
@@ -117,48 +165,87 @@ func RunWithOptions(ctx context.Context, app_opts *RunOptions) error {
 		return fmt.Errorf("Failed to ensure ?metadata=skip for tmpfile URI %s, %w", tmpfile_uri, err)
 	}
 
-	source_bucket, err := bucket.NewBucket(ctx, source_uri)
+	source_bucket, err := buckets.Open(ctx, source_uri)
 
 	if err != nil {
 		return fmt.Errorf("Failed to open source bucket, %w", err)
 	}
 
-	target_bucket, err := bucket.NewBucket(ctx, target_uri)
+	target_uri, err := app_opts.targetBucketURI()
+
+	if err != nil {
+		return err
+	}
+
+	target_bucket, err := buckets.Open(ctx, target_uri)
 
 	if err != nil {
 		return fmt.Errorf("Failed to open target bucket, %w", err)
 	}
 
-	tmpfile_bucket, err := bucket.NewBucket(ctx, tmpfile_uri)
+	tmpfile_bucket, err := buckets.Open(ctx, tmpfile_uri)
 
 	if err != nil {
 		return fmt.Errorf("Failed to open tmpfile bucket, %w", err)
 	}
 
-	pb_opts, err := pb.NewPictureBookDefaultOptions(ctx)
+	var cache_bucket bucket.Bucket
+
+	if app_opts.CacheBucketURI != "" {
+
+		cache_uri, err := ensureScheme(app_opts.CacheBucketURI)
+
+		if err != nil {
+			return fmt.Errorf("Failed to ensure scheme for cache URI %s, %w", app_opts.CacheBucketURI, err)
+		}
+
+		cache_uri, err = ensureSkipMetadata(cache_uri)
+
+		if err != nil {
+			return fmt.Errorf("Failed to ensure ?metadata=skip for cache URI %s, %w", cache_uri, err)
+		}
+
+		cache_bucket, err = buckets.Open(ctx, cache_uri)
+
+		if err != nil {
+			return fmt.Errorf("Failed to open cache bucket, %w", err)
+		}
+	}
+
+	var preview_bucket bucket.Bucket
+
+	if app_opts.PreviewBucketURI != "" {
+
+		preview_uri, err := ensureScheme(app_opts.PreviewBucketURI)
+
+		if err != nil {
+			return fmt.Errorf("Failed to ensure scheme for preview URI %s, %w", app_opts.PreviewBucketURI, err)
+		}
+
+		preview_uri, err = ensureSkipMetadata(preview_uri)
+
+		if err != nil {
+			return fmt.Errorf("Failed to ensure ?metadata=skip for preview URI %s, %w", preview_uri, err)
+		}
+
+		preview_bucket, err = buckets.Open(ctx, preview_uri)
+
+		if err != nil {
+			return fmt.Errorf("Failed to open preview bucket, %w", err)
+		}
+	}
+
+	pb_opts, err := newPictureBookOptions(ctx, app_opts)
 
 	if err != nil {
-		return fmt.Errorf("Failed to create default picturebook options, %w", err)
+		return err
 	}
 
-	pb_opts.Orientation = app_opts.Orientation
-	pb_opts.Size = app_opts.Size
-	pb_opts.Width = app_opts.Width
-	pb_opts.Height = app_opts.Height
-	pb_opts.Units = app_opts.Units
-	pb_opts.DPI = app_opts.DPI
-	pb_opts.Border = app_opts.Border
-	pb_opts.Bleed = app_opts.Bleed
-	pb_opts.MarginTop = app_opts.MarginTop
-	pb_opts.MarginBottom = app_opts.MarginBottom
-	pb_opts.MarginLeft = app_opts.MarginLeft
-	pb_opts.MarginRight = app_opts.MarginRight
-	pb_opts.FillPage = app_opts.FillPage
-	pb_opts.Verbose = app_opts.Verbose
-	pb_opts.OCRAFont = app_opts.OCRAFont
-	pb_opts.EvenOnly = app_opts.EvenOnly
-	pb_opts.OddOnly = app_opts.OddOnly
-	pb_opts.MaxPages = app_opts.MaxPages
+	pb_opts.Preview = preview_bucket
+
+	if pb_spec != nil {
+		pb_spec.Apply(pb_opts)
+	}
 
 	processed := make([]string, 0)
 
@@ -180,34 +267,6 @@ func RunWithOptions(ctx context.Context, app_opts *RunOptions) error {
 		}
 	}()
 
-	if len(app_opts.FilterURIs) > 0 {
-
-		filters := make([]filter.Filter, len(app_opts.FilterURIs))
-
-		for idx, filter_uri := range app_opts.FilterURIs {
-
-			if !uri_re.MatchString(filter_uri) {
-				filter_uri = fmt.Sprintf("%s://", filter_uri)
-			}
-
-			f, err := filter.NewFilter(ctx, filter_uri)
-
-			if err != nil {
-				return fmt.Errorf("Failed to create filter '%s', %w", filter_uri, err)
-			}
-
-			filters[idx] = f
-		}
-
-		multi, err := filter.NewMultiFilter(ctx, filters...)
-
-		if err != nil {
-			return fmt.Errorf("Failed to create multi filter, %w", err)
-		}
-
-		pb_opts.Filter = multi
-	}
-
 	if len(app_opts.ProcessURIs) > 0 {
 
 		processes := make([]process.Process, len(app_opts.ProcessURIs))
@@ -228,7 +287,13 @@ func RunWithOptions(ctx context.Context, app_opts *RunOptions) error {
 			}
 		}
 
-		multi, err := process.NewMultiProcess(ctx, processes...)
+		multi_opts := &process.MultiProcessOptions{
+			Processes: processes,
+			URIs:      app_opts.ProcessURIs,
+			Cache:     cache_bucket,
+		}
+
+		multi, err := process.NewMultiProcessWithOptions(ctx, multi_opts)
 
 		if err != nil {
 			return fmt.Errorf("Failed to create multi process, %w", err)
@@ -248,20 +313,223 @@ func RunWithOptions(ctx context.Context, app_opts *RunOptions) error {
 		}
 	}
 
+	if app_opts.SortURI != "" {
+
+		s, err := sort.NewSorter(ctx, app_opts.SortURI)
+
+		if err != nil {
+			return fmt.Errorf("Failed to create new sorter, %w", err)
+		}
+
+		pb_opts.Sort = s
+	}
+
+	filename := app_opts.Filename
+	to_stdout := filename == STDOUT
+
+	if to_stdout && (app_opts.Debug || app_opts.Manifest != "") {
+		return fmt.Errorf("The -debug and -manifest flags can not be used when writing a picturebook to STDOUT")
+	}
+
+	switch app_opts.Summary {
+	case "", SUMMARY_FORMAT_TEXT, SUMMARY_FORMAT_JSON:
+		// pass
+	default:
+		return fmt.Errorf("Invalid or unsupported summary format '%s'", app_opts.Summary)
+	}
+
+	if app_opts.OutputURI != "" {
+
+		o, err := output.NewOutput(ctx, app_opts.OutputURI)
+
+		if err != nil {
+			return fmt.Errorf("Failed to create new output, %w", err)
+		}
+
+		_, is_writer := o.(output.WriterOutput)
+
+		if to_stdout && !is_writer {
+			return fmt.Errorf("Output %s can not be written to STDOUT", app_opts.OutputURI)
+		}
+
+		filename = documentFilename(filename, o)
+
+		pb_opts.Output = o
+	}
+
+	if pb_opts.Title == "" && to_stdout {
+		pb_opts.Title = "picturebook"
+	} else if pb_opts.Title == "" {
+		pb_opts.Title = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+
+	pb_opts.Source = source_bucket
+	pb_opts.Target = target_bucket
+	pb_opts.Temporary = tmpfile_bucket
+	pb_opts.Cache = cache_bucket
+	pb_opts.Monitor = monitor
+
+	pb, err := pb.NewPictureBook(ctx, pb_opts)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create new picturebook, %v", err)
+	}
+
+	if pb_spec != nil {
+
+		slog.Info("Add pages", "spec", app_opts.Spec, "count", len(pb_spec.Pages))
+		err = pb_spec.AddPages(ctx, pb)
+
+		if err != nil {
+			return fmt.Errorf("Failed to add pages from spec to picturebook, %w", err)
+		}
+
+	} else {
+
+		slog.Info("Add pictures", "sources", app_opts.Sources)
+		err = pb.AddPictures(ctx, app_opts.Sources)
+
+		if err != nil {
+			return fmt.Errorf("Failed to add pictures to picturebook, %w", err)
+		}
+	}
+
+	if pb_opts.Preflight != nil {
+
+		warnings := pb.PreflightWarnings()
+
+		err = WritePreflightReport(os.Stderr, warnings)
+
+		if err != nil {
+			return fmt.Errorf("Failed to write preflight report, %w", err)
+		}
+
+		if app_opts.PreflightStrict && len(warnings) > 0 {
+			return fmt.Errorf("Picturebook failed %d preflight checks", len(warnings))
+		}
+	}
+
+	if to_stdout {
+		err = pb.SaveTo(ctx, os.Stdout)
+	} else {
+		err = pb.Save(ctx, filename)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed to save picturebook, %w", err)
+	}
+
+	skipped := pb.SkippedPictures()
+
+	if len(skipped) > 0 {
+
+		err = WriteSkippedReport(os.Stderr, skipped)
+
+		if err != nil {
+			return fmt.Errorf("Failed to write skipped pictures report, %w", err)
+		}
+	}
+
+	if app_opts.Summary != "" {
+
+		err = WriteBuildSummary(os.Stderr, pb.Summary(), app_opts.Summary)
+
+		if err != nil {
+			return fmt.Errorf("Failed to write build summary, %w", err)
+		}
+	}
+
+	return nil
+}
+
+// newPictureBookOptions returns a new `picturebook.PictureBookOptions` instance derived from 'app_opts'. This includes page
+// settings as well as any filters, captions and text but not any of the buckets, processes or outputs which are left to the caller.
+func newPictureBookOptions(ctx context.Context, app_opts *RunOptions) (*pb.PictureBookOptions, error) {
+
+	pb_opts, err := pb.NewPictureBookDefaultOptions(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create default picturebook options, %w", err)
+	}
+
+	pb_opts.Orientation = app_opts.Orientation
+	pb_opts.Size = app_opts.Size
+	pb_opts.Width = app_opts.Width
+	pb_opts.Height = app_opts.Height
+	pb_opts.Units = app_opts.Units
+	pb_opts.DPI = app_opts.DPI
+	pb_opts.Border = app_opts.Border
+	pb_opts.Bleed = app_opts.Bleed
+	pb_opts.MarginTop = app_opts.MarginTop
+	pb_opts.MarginBottom = app_opts.MarginBottom
+	pb_opts.MarginLeft = app_opts.MarginLeft
+	pb_opts.MarginRight = app_opts.MarginRight
+	pb_opts.FillPage = app_opts.FillPage
+	pb_opts.Verbose = app_opts.Verbose
+	pb_opts.OCRAFont = app_opts.OCRAFont
+	pb_opts.EvenOnly = app_opts.EvenOnly
+	pb_opts.OddOnly = app_opts.OddOnly
+	pb_opts.MaxPages = app_opts.MaxPages
+	pb_opts.Workers = app_opts.Workers
+	pb_opts.Debug = app_opts.Debug
+	pb_opts.Manifest = app_opts.Manifest
+	pb_opts.Title = app_opts.Title
+	pb_opts.PreviewDPI = app_opts.PreviewDPI
+	pb_opts.ErrorPolicy = app_opts.ErrorPolicy
+
+	if app_opts.Preflight || app_opts.PreflightStrict {
+
+		pb_opts.Preflight = &pb.PreflightOptions{
+			MinDPI:         app_opts.PreflightMinDPI,
+			MaxAspectRatio: app_opts.PreflightMaxAspectRatio,
+		}
+	}
+
+	if len(app_opts.FilterURIs) > 0 {
+
+		filters := make([]filter.Filter, len(app_opts.FilterURIs))
+		uris := make([]string, len(app_opts.FilterURIs))
+
+		for idx, filter_uri := range app_opts.FilterURIs {
+
+			filter_uri = ensureHandlerScheme(filter_uri)
+			uris[idx] = filter_uri
+
+			f, err := filter.NewFilter(ctx, filter_uri)
+
+			if err != nil {
+				return nil, fmt.Errorf("Failed to create filter '%s', %w", filter_uri, err)
+			}
+
+			filters[idx] = f
+		}
+
+		multi_opts := &filter.MultiFilterOptions{
+			Filters: filters,
+			URIs:    uris,
+		}
+
+		multi, err := filter.NewMultiFilterWithOptions(ctx, multi_opts)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create multi filter, %w", err)
+		}
+
+		pb_opts.Filter = multi
+	}
+
 	if len(app_opts.CaptionURIs) > 0 {
 
 		captions := make([]caption.Caption, len(app_opts.CaptionURIs))
 
 		for idx, c_uri := range app_opts.CaptionURIs {
 
-			if !uri_re.MatchString(c_uri) {
-				c_uri = fmt.Sprintf("%s://", c_uri)
-			}
+			c_uri = ensureHandlerScheme(c_uri)
 
 			c, err := caption.NewCaption(ctx, c_uri)
 
 			if err != nil {
-				return fmt.Errorf("Failed to create new caption for '%s', %w", c_uri, err)
+				return nil, fmt.Errorf("Failed to create new caption for '%s', %w", c_uri, err)
 			}
 
 			captions[idx] = c
@@ -276,7 +544,7 @@ func RunWithOptions(ctx context.Context, app_opts *RunOptions) error {
 		c, err := caption.NewMultiCaptionWithOptions(ctx, c_opts)
 
 		if err != nil {
-			return fmt.Errorf("Failed to create multi caption, %w", err)
+			return nil, fmt.Errorf("Failed to create multi caption, %w", err)
 		}
 
 		pb_opts.Caption = c
@@ -284,76 +552,61 @@ func RunWithOptions(ctx context.Context, app_opts *RunOptions) error {
 
 	if app_opts.TextURI != "" {
 
-		if !uri_re.MatchString(app_opts.TextURI) {
-			app_opts.TextURI = fmt.Sprintf("%s://", app_opts.TextURI)
-		}
-
-		t, err := text.NewText(ctx, app_opts.TextURI)
+		t, err := text.NewText(ctx, ensureHandlerScheme(app_opts.TextURI))
 
 		if err != nil {
-			return fmt.Errorf("Failed to create new text, %w", err)
+			return nil, fmt.Errorf("Failed to create new text, %w", err)
 		}
 
 		pb_opts.Text = t
 	}
 
-	if app_opts.SortURI != "" {
-
-		s, err := sort.NewSorter(ctx, app_opts.SortURI)
-
-		if err != nil {
-			return fmt.Errorf("Failed to create new sorter, %w", err)
-		}
-
-		pb_opts.Sort = s
-	}
+	return pb_opts, nil
+}
 
-	if len(app_opts.Sources) == 0 {
+// loadSpec returns the spec file defined by `app_opts.Spec` and the URI of the bucket its images are read from. When images
+// are read from the (default) local filesystem relative image paths are resolved relative to the spec file.
+func loadSpec(ctx context.Context, app_opts *RunOptions) (*spec.Spec, string, error) {
 
-		base := filepath.Base(source_uri)
-		root := filepath.Dir(source_uri)
+	source_uri := app_opts.SourceBucketURI
 
-		sb, err := bucket.NewBucket(ctx, root)
+	s, err := spec.NewSpecFromPath(ctx, app_opts.Spec)
 
-		if err != nil {
-			return fmt.Errorf("Failed to open bucket for %s, %w", root, err)
-		}
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to load spec from %s, %w", app_opts.Spec, err)
+	}
 
-		source_bucket = sb
-		app_opts.Sources = []string{base}
+	if source_uri != "" && source_uri != "file:///" {
+		return s, source_uri, nil
 	}
 
-	monitor, err := progress.NewMonitor(ctx, app_opts.ProgressMonitorURI)
+	abs_spec, err := filepath.Abs(app_opts.Spec)
 
 	if err != nil {
-		return fmt.Errorf("Failed to create new progress monitor, %w", err)
+		return nil, "", fmt.Errorf("Failed to derive absolute path for %s, %w", app_opts.Spec, err)
 	}
 
-	pb_opts.Source = source_bucket
-	pb_opts.Target = target_bucket
-	pb_opts.Temporary = tmpfile_bucket
-	pb_opts.Monitor = monitor
+	root := filepath.Dir(abs_spec)
 
-	pb, err := pb.NewPictureBook(ctx, pb_opts)
+	for _, p := range s.Pages {
 
-	if err != nil {
-		return fmt.Errorf("Failed to create new picturebook, %v", err)
+		if p.Path != "" && !filepath.IsAbs(p.Path) {
+			p.Path = filepath.Join(root, p.Path)
+		}
 	}
 
-	slog.Info("Add pictures", "sources", app_opts.Sources)
-	err = pb.AddPictures(ctx, app_opts.Sources)
-
-	if err != nil {
-		return fmt.Errorf("Failed to add pictures to picturebook, %w", err)
-	}
+	return s, "file:///", nil
+}
 
-	err = pb.Save(ctx, app_opts.Filename)
+// ensureHandlerScheme ensures that 'uri' (for example a filter or caption URI) has a URI scheme. If it does not then 'uri'
+// is assumed to be the name of the scheme and "://" is appended to it.
+func ensureHandlerScheme(uri string) string {
 
-	if err != nil {
-		return fmt.Errorf("Failed to save picturebook, %w", err)
+	if !uri_re.MatchString(uri) {
+		return fmt.Sprintf("%s://", uri)
 	}
 
-	return nil
+	return uri
 }
 
 // ensureScheme ensures that 'uri' has a valid URI scheme. If the scheme is empty then a default of "file" is applied to 'uri'.
@@ -0,0 +1,63 @@
+package picturebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/aaronland/go-picturebook/progress"
+)
+
+func TestRunWithBucketsReportsSetupErrors(t *testing.T) {
+
+	ctx := context.Background()
+
+	payloads := make([]*progress.WebhookPayload, 0)
+	mu := new(sync.Mutex)
+
+	s := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+
+		var p *progress.WebhookPayload
+
+		err := json.NewDecoder(req.Body).Decode(&p)
+
+		if err != nil {
+			http.Error(rsp, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		payloads = append(payloads, p)
+		mu.Unlock()
+	}))
+
+	defer s.Close()
+
+	// The spec file doesn't exist so the picturebook fails before any buckets are opened
+
+	opts := &RunOptions{
+		Spec:               filepath.Join(t.TempDir(), "missing.yaml"),
+		ProgressMonitorURI: fmt.Sprintf("webhook://?url=%s&every=1h", s.URL),
+	}
+
+	buckets := NewBuckets()
+	defer buckets.Close()
+
+	err := RunWithBuckets(ctx, opts, buckets)
+
+	if err == nil {
+		t.Fatalf("Expected missing spec file to fail")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(payloads) != 1 || payloads[0].Status != progress.WEBHOOK_STATUS_FAILED || payloads[0].Error != err.Error() {
+		t.Fatalf("Expected a single failed payload, %v", payloads)
+	}
+}
@@ -0,0 +1,38 @@
+package picturebook
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	pb "github.com/aaronland/go-picturebook"
+)
+
+// WritePreflightReport writes a table describing each of the problems in 'warnings' to 'wr'.
+func WritePreflightReport(wr io.Writer, warnings []*pb.PreflightWarning) error {
+
+	if len(warnings) == 0 {
+		_, err := fmt.Fprintln(wr, "All images passed preflight checks")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(wr, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "PAGE\tSOURCE\tCHECK\tDPI\tMESSAGE\t")
+
+	pages := make(map[int]bool)
+
+	for _, w := range warnings {
+		pages[w.Page] = true
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%.0f\t%s\t\n", w.Page, w.Source, w.Check, w.EffectiveDPI, w.Message)
+	}
+
+	err := tw.Flush()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(wr, "\n%d preflight warnings on %d pages\n", len(warnings), len(pages))
+	return err
+}
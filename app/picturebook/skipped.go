@@ -0,0 +1,37 @@
+package picturebook
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	pb "github.com/aaronland/go-picturebook"
+)
+
+// WriteSkippedReport writes a table listing each of the files in 'skipped', and the reason it was not added to a picturebook, to 'wr'.
+func WriteSkippedReport(wr io.Writer, skipped []*pb.SkippedPicture) error {
+
+	tw := tabwriter.NewWriter(wr, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "SKIPPED\tREASON\t")
+
+	for _, s := range skipped {
+
+		path := s.Path
+
+		if path == "" {
+			path = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t\n", path, s.Reason)
+	}
+
+	err := tw.Flush()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(wr, "\n%d files were not added to the picturebook\n", len(skipped))
+	return err
+}
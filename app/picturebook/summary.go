@@ -0,0 +1,89 @@
+package picturebook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/aaronland/go-picturebook"
+	"github.com/aaronland/go-picturebook/picture"
+	"github.com/aaronland/go-picturebook/progress"
+)
+
+// SUMMARY_FORMAT_TEXT is the value of the -summary flag used to write a build summary as plain text.
+const SUMMARY_FORMAT_TEXT string = "text"
+
+// SUMMARY_FORMAT_JSON is the value of the -summary flag used to write a build summary as JSON.
+const SUMMARY_FORMAT_JSON string = "json"
+
+// WriteBuildSummary writes 's' to 'wr' encoded as 'format' which is expected to be one of `SUMMARY_FORMAT_TEXT` or `SUMMARY_FORMAT_JSON`.
+func WriteBuildSummary(wr io.Writer, s *pb.BuildSummary, format string) error {
+
+	switch format {
+	case SUMMARY_FORMAT_JSON:
+
+		enc := json.NewEncoder(wr)
+		enc.SetEscapeHTML(false)
+
+		return enc.Encode(s)
+
+	case SUMMARY_FORMAT_TEXT:
+		// pass
+	default:
+		return fmt.Errorf("Invalid or unsupported summary format '%s'", format)
+	}
+
+	tw := tabwriter.NewWriter(wr, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Files\t%d\t\n", s.Files)
+	fmt.Fprintf(tw, "Not images\t%d\t\n", s.NotImages)
+	fmt.Fprintf(tw, "Skipped\t%d\t\n", s.Skipped)
+
+	filtered := 0
+
+	for _, count := range s.Filtered {
+		filtered += count
+	}
+
+	fmt.Fprintf(tw, "Filtered\t%d\t\n", filtered)
+
+	for _, uri := range slices.Sorted(maps.Keys(s.Filtered)) {
+		fmt.Fprintf(tw, "  %s\t%d\t\n", uri, s.Filtered[uri])
+	}
+
+	fmt.Fprintf(tw, "Processed\t%d\t\n", s.Processed)
+	fmt.Fprintf(tw, "Captioned\t%d\t\n", s.Captioned)
+
+	pages := 0
+
+	for _, count := range s.Pages {
+		pages += count
+	}
+
+	fmt.Fprintf(tw, "Pages\t%d\t\n", pages)
+
+	page_types := []string{
+		picture.PAGE_TYPE_PICTURE,
+		picture.PAGE_TYPE_TEXT,
+		picture.PAGE_TYPE_SECTION,
+		picture.PAGE_TYPE_BLANK,
+	}
+
+	for _, t := range page_types {
+		fmt.Fprintf(tw, "  %s\t%d\t\n", t, s.Pages[t])
+	}
+
+	fmt.Fprintf(tw, "Output size\t%d bytes\t\n", s.OutputSize)
+
+	for _, stage := range progress.Stages {
+		fmt.Fprintf(tw, "Time (%s)\t%s\t\n", stage, s.Timings[stage].Round(time.Millisecond))
+	}
+
+	fmt.Fprintf(tw, "Time (total)\t%s\t\n", s.Duration.Round(time.Millisecond))
+
+	return tw.Flush()
+}
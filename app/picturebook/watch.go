@@ -0,0 +1,238 @@
+package picturebook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aaronland/go-picturebook/bucket"
+)
+
+// watch_extensions is the list of filename extensions for images whose changes trigger a rebuild in watch mode.
+var watch_extensions = []string{
+	".gif",
+	".heic",
+	".jpeg",
+	".jpg",
+	".png",
+	".tif",
+	".tiff",
+	".webp",
+}
+
+// runWatch creates the picturebook described by 'app_opts' and then recreates it whenever images in its sources
+// (or its spec file, or the images it references) are added, removed or modified, until 'ctx' is cancelled or the process is interrupted. Sources
+// are polled every `WatchInterval` and the picturebook is only recreated once they have stopped changing, which is to
+// say when two consecutive polls are the same. If `CacheBucketURI` is empty a temporary cache is used, and removed on
+// exit, so that processed images are reused between builds.
+func runWatch(ctx context.Context, app_opts *RunOptions) error {
+
+	if app_opts.Filename == STDOUT {
+		return fmt.Errorf("The -watch flag can not be used when writing a picturebook to STDOUT")
+	}
+
+	if app_opts.WatchInterval <= 0 {
+		return fmt.Errorf("Invalid watch interval")
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	watch_opts := app_opts.Clone()
+	watch_opts.Watch = false
+
+	if watch_opts.CacheBucketURI == "" {
+
+		cache_root, err := os.MkdirTemp("", "picturebook-cache-")
+
+		if err != nil {
+			return fmt.Errorf("Failed to create temporary cache, %w", err)
+		}
+
+		defer os.RemoveAll(cache_root)
+
+		watch_opts.CacheBucketURI = fmt.Sprintf("file://%s", cache_root)
+	}
+
+	build := func() {
+
+		// RunWithOptions may modify its options so always start from a copy
+
+		opts := watch_opts.Clone()
+
+		t1 := time.Now()
+		err := RunWithOptions(ctx, opts)
+
+		if err != nil {
+			slog.Error("Failed to build picturebook", "error", err)
+			return
+		}
+
+		slog.Info("Picturebook built", "filename", opts.Filename, "time", time.Since(t1))
+	}
+
+	built, err := watchSnapshot(ctx, watch_opts)
+
+	if err != nil {
+		return err
+	}
+
+	build()
+
+	previous := built
+
+	ticker := time.NewTicker(watch_opts.WatchInterval)
+	defer ticker.Stop()
+
+	slog.Info("Watching for changes", "sources", watch_opts.Sources, "spec", watch_opts.Spec, "interval", watch_opts.WatchInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+
+			current, err := watchSnapshot(ctx, watch_opts)
+
+			if err != nil {
+				slog.Warn("Failed to check sources for changes", "error", err)
+				continue
+			}
+
+			if maps.Equal(current, built) {
+				previous = current
+				continue
+			}
+
+			if !maps.Equal(current, previous) {
+				slog.Debug("Changes detected, waiting for sources to stop changing")
+				previous = current
+				continue
+			}
+
+			slog.Info("Rebuild picturebook")
+			build()
+
+			built = current
+		}
+	}
+}
+
+// watchSnapshot returns a map of the images in the sources (and the spec file, and the images it references) defined by
+// 'app_opts' to a string derived from their modification time and size.
+func watchSnapshot(ctx context.Context, app_opts *RunOptions) (map[string]string, error) {
+
+	snapshot := make(map[string]string)
+
+	source_uri := app_opts.SourceBucketURI
+
+	// The images referenced by the pages in the spec file, if present
+
+	spec_paths := make([]string, 0)
+
+	if app_opts.Spec != "" {
+
+		info, err := os.Stat(app_opts.Spec)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to stat %s, %w", app_opts.Spec, err)
+		}
+
+		snapshot[app_opts.Spec] = fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+
+		s, spec_source_uri, err := loadSpec(ctx, app_opts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		source_uri = spec_source_uri
+
+		for _, p := range s.Pages {
+
+			if p.Path != "" {
+				spec_paths = append(spec_paths, p.Path)
+			}
+		}
+	}
+
+	if source_uri == "" {
+		source_uri = "file:///"
+	}
+
+	source_uri, err := ensureScheme(source_uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to ensure scheme for source URI %s, %w", source_uri, err)
+	}
+
+	paths := app_opts.Sources
+
+	// This mirrors what RunWithOptions does when no sources are defined
+
+	if len(paths) == 0 && app_opts.Spec == "" {
+		paths = []string{filepath.Base(source_uri)}
+		source_uri = filepath.Dir(source_uri)
+	}
+
+	if len(paths) == 0 && len(spec_paths) == 0 {
+		return snapshot, nil
+	}
+
+	source_bucket, err := bucket.NewBucket(ctx, source_uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open source bucket, %w", err)
+	}
+
+	defer source_bucket.Close()
+
+	for _, path := range spec_paths {
+
+		attrs, err := source_bucket.Attributes(ctx, path)
+
+		// Images which are missing, for example while they are being replaced, are recorded rather than
+		// returned as an error so that the picturebook is rebuilt once they have been restored
+
+		if err != nil {
+			slog.Debug("Failed to derive attributes for spec image", "path", path, "error", err)
+			snapshot[path] = "missing"
+			continue
+		}
+
+		snapshot[path] = fmt.Sprintf("%d:%d", attrs.ModTime.UnixNano(), attrs.Size)
+	}
+
+	if len(paths) == 0 {
+		return snapshot, nil
+	}
+
+	for path, err := range source_bucket.GatherPictures(ctx, paths...) {
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to gather pictures, %w", err)
+		}
+
+		if !slices.Contains(watch_extensions, strings.ToLower(filepath.Ext(path))) {
+			continue
+		}
+
+		attrs, err := source_bucket.Attributes(ctx, path)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to derive attributes for %s, %w", path, err)
+		}
+
+		snapshot[path] = fmt.Sprintf("%d:%d", attrs.ModTime.UnixNano(), attrs.Size)
+	}
+
+	return snapshot, nil
+}
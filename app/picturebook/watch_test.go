@@ -0,0 +1,127 @@
+package picturebook
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestWatchSnapshot(t *testing.T) {
+
+	ctx := context.Background()
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to register buckets, %v", err)
+	}
+
+	root := t.TempDir()
+
+	write := func(fname string, body string) {
+
+		err := os.WriteFile(filepath.Join(root, fname), []byte(body), 0644)
+
+		if err != nil {
+			t.Fatalf("Failed to write %s, %v", fname, err)
+		}
+	}
+
+	write("a.jpg", "a")
+
+	opts := &RunOptions{
+		SourceBucketURI: "file:///",
+		Sources:         []string{root},
+	}
+
+	first, err := watchSnapshot(ctx, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to derive snapshot, %v", err)
+	}
+
+	if len(first) != 1 {
+		t.Fatalf("Unexpected snapshot %v", first)
+	}
+
+	// Files which aren't images are ignored
+
+	write("notes.txt", "notes")
+
+	second, err := watchSnapshot(ctx, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to derive snapshot, %v", err)
+	}
+
+	if !maps.Equal(first, second) {
+		t.Fatalf("Expected snapshot to ignore non-image files")
+	}
+
+	// Images which are added or modified are not
+
+	write("b.png", "b")
+
+	then := time.Now().Add(-1 * time.Hour)
+
+	err = os.Chtimes(filepath.Join(root, "a.jpg"), then, then)
+
+	if err != nil {
+		t.Fatalf("Failed to modify a.jpg, %v", err)
+	}
+
+	third, err := watchSnapshot(ctx, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to derive snapshot, %v", err)
+	}
+
+	if len(third) != 2 {
+		t.Fatalf("Unexpected snapshot %v", third)
+	}
+
+	for path, v := range first {
+
+		if third[path] == v {
+			t.Fatalf("Expected modified image %s to change snapshot", path)
+		}
+	}
+
+	// Images referenced by a spec file, relative to the spec file, are watched too
+
+	write("book.json", `{"pages": [{"type": "picture", "path": "a.jpg"}]}`)
+
+	spec_opts := &RunOptions{
+		Spec: filepath.Join(root, "book.json"),
+	}
+
+	fourth, err := watchSnapshot(ctx, spec_opts)
+
+	if err != nil {
+		t.Fatalf("Failed to derive snapshot, %v", err)
+	}
+
+	a_path := filepath.Join(root, "a.jpg")
+
+	if len(fourth) != 2 || fourth[a_path] == "" {
+		t.Fatalf("Unexpected snapshot %v", fourth)
+	}
+
+	write("a.jpg", "a modified image")
+
+	fifth, err := watchSnapshot(ctx, spec_opts)
+
+	if err != nil {
+		t.Fatalf("Failed to derive snapshot, %v", err)
+	}
+
+	if fifth[a_path] == fourth[a_path] {
+		t.Fatalf("Expected modified spec image to change snapshot")
+	}
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aaronland/go-picturebook/app/picturebook"
+	"github.com/aaronland/go-picturebook/spec"
+)
+
+// ErrNotAllowed is returned by `JobQueue.Submit` when a job reads from, or writes to, a location it is not allowed to.
+var ErrNotAllowed = errors.New("Job is not allowed")
+
+// ensureAllowed returns `ErrNotAllowed` if 'opts' reads from, or writes to, a location other than the queue's default buckets
+// or a location below one of the queue's allowed roots. This includes bucket URIs, the output URI, the spec file (and the
+// images it references) and, when images are read from the local filesystem, each of the job's sources.
+func (q *JobQueue) ensureAllowed(ctx context.Context, opts *picturebook.RunOptions) error {
+
+	defaults := q.defaults
+
+	uris := []struct {
+		label    string
+		value    string
+		fallback string
+	}{
+		{"SourceBucketURI", opts.SourceBucketURI, defaults.SourceBucketURI},
+		{"TargetBucketURI", opts.TargetBucketURI, defaults.TargetBucketURI},
+		{"TempBucketURI", opts.TempBucketURI, defaults.TempBucketURI},
+		{"CacheBucketURI", opts.CacheBucketURI, defaults.CacheBucketURI},
+		{"PreviewBucketURI", opts.PreviewBucketURI, defaults.PreviewBucketURI},
+		{"OutputURI", opts.OutputURI, defaults.OutputURI},
+	}
+
+	// Images are read from the local filesystem if the source bucket is empty, or file:///, in which case each of the
+	// job's sources (or the images in its spec file) is checked below
+
+	local := opts.SourceBucketURI == "" || opts.SourceBucketURI == "file:///"
+
+	for _, u := range uris {
+
+		if u.value == u.fallback {
+			continue
+		}
+
+		if local && u.label == "SourceBucketURI" {
+			continue
+		}
+
+		// An empty target bucket means the current working directory. Any other empty value disables the feature or,
+		// in the case of TempBucketURI, uses the operating system's temporary directory
+
+		if u.value == "" && u.label != "TargetBucketURI" {
+			continue
+		}
+
+		if !q.allowedURI(u.value) {
+			return fmt.Errorf("%w, %s '%s' is not allowed", ErrNotAllowed, u.label, u.value)
+		}
+	}
+
+	err := ensureBucketKey(opts.Filename)
+
+	if err != nil {
+		return fmt.Errorf("%w, Filename %w", ErrNotAllowed, err)
+	}
+
+	paths := make([]string, 0)
+
+	if opts.Spec != "" {
+
+		if opts.Spec != defaults.Spec && !q.allowedPath(opts.Spec) {
+			return fmt.Errorf("%w, Spec '%s' is not allowed", ErrNotAllowed, opts.Spec)
+		}
+
+		s, err := spec.NewSpecFromPath(ctx, opts.Spec)
+
+		if err != nil {
+			return fmt.Errorf("Failed to load spec, %w", err)
+		}
+
+		// Relative paths are resolved relative to the spec file when images are read from the local filesystem. See
+		// the `loadSpec` function in the app/picturebook package for details.
+
+		root := filepath.Dir(opts.Spec)
+
+		for _, p := range s.Pages {
+
+			switch {
+			case p.Path == "":
+				// pass
+			case local && !filepath.IsAbs(p.Path):
+				paths = append(paths, filepath.Join(root, p.Path))
+			default:
+				paths = append(paths, p.Path)
+			}
+		}
+
+	} else {
+		paths = append(paths, opts.Sources...)
+	}
+
+	for _, p := range paths {
+
+		if local {
+
+			if !q.allowedPath(p) {
+				return fmt.Errorf("%w, '%s' is not allowed", ErrNotAllowed, p)
+			}
+
+			continue
+		}
+
+		err := ensureBucketKey(p)
+
+		if err != nil {
+			return fmt.Errorf("%w, %w", ErrNotAllowed, err)
+		}
+	}
+
+	return nil
+}
+
+// allowedURI returns a boolean value signaling whether 'uri' is below one of the queue's allowed roots.
+func (q *JobQueue) allowedURI(uri string) bool {
+
+	for _, root := range q.roots {
+
+		if uriUnderRoot(uri, root) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedPath returns a boolean value signaling whether the local file at 'p' is below one of the queue's allowed roots.
+func (q *JobQueue) allowedPath(p string) bool {
+
+	abs_path, err := filepath.Abs(p)
+
+	if err != nil {
+		return false
+	}
+
+	return q.allowedURI("file://" + filepath.ToSlash(abs_path))
+}
+
+// uriUnderRoot returns a boolean value signaling whether 'uri' has the same scheme, host and query parameters (other than
+// "metadata") as 'root' and whether its path is the same as, or below, the path of 'root'. URIs without a scheme are
+// assumed to be file:// URIs.
+func uriUnderRoot(uri string, root string) bool {
+
+	u, err := parseAccessURI(uri)
+
+	if err != nil {
+		return false
+	}
+
+	root_u, err := parseAccessURI(root)
+
+	if err != nil {
+		return false
+	}
+
+	if u.Scheme != root_u.Scheme || u.Host != root_u.Host {
+		return false
+	}
+
+	q := u.Query()
+	q.Del("metadata")
+
+	root_q := root_u.Query()
+	root_q.Del("metadata")
+
+	if q.Encode() != root_q.Encode() {
+		return false
+	}
+
+	u_path := path.Clean("/" + u.Path)
+	root_path := path.Clean("/" + root_u.Path)
+
+	return root_path == "/" || u_path == root_path || strings.HasPrefix(u_path, root_path+"/")
+}
+
+// parseAccessURI parses 'uri' assigning it a file:// scheme if it does not have one.
+func parseAccessURI(uri string) (*url.URL, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "" {
+		u.Scheme = "file"
+	}
+
+	return u, nil
+}
+
+// ensureBucketKey returns an error if 'key' refers to a location outside the bucket it is read from, or written to.
+func ensureBucketKey(key string) error {
+
+	clean_key := path.Clean(filepath.ToSlash(key))
+
+	if clean_key == ".." || strings.HasPrefix(clean_key, "../") {
+		return fmt.Errorf("'%s' is outside its bucket", key)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"flag"
+
+	"github.com/sfomuseum/go-flags/flagset"
+	"github.com/sfomuseum/go-flags/multi"
+)
+
+// The URI of the HTTP server to listen for requests on.
+var server_uri string
+
+// The maximum number of picturebook jobs to run concurrently.
+var workers int
+
+// The maximum number of picturebook jobs waiting to be run.
+var queue_size int
+
+// The path to an optional picturebook config file defining default values for picturebook jobs.
+var config_path string
+
+// The name of a profile, defined in config_path, whose values are applied to picturebook jobs.
+var config_profile string
+
+// Zero or more URIs (or local paths) below which picturebook jobs may read from, or write to, locations other than the default source and target buckets.
+var allow_uris multi.MultiString
+
+// DefaultFlagSet returns a `flag.FlagSet` instance with flags for running the `picturebook-server` application.
+func DefaultFlagSet(ctx context.Context) (*flag.FlagSet, error) {
+
+	fs := flagset.NewFlagSet("picturebook-server")
+
+	fs.StringVar(&server_uri, "server-uri", "http://localhost:8080", "The URI of the HTTP server to listen for requests on.")
+	fs.IntVar(&workers, "workers", 1, "The maximum number of picturebook jobs to run concurrently.")
+	fs.IntVar(&queue_size, "queue-size", 10, "The maximum number of picturebook jobs waiting to be run. Jobs submitted when the queue is full are rejected.")
+
+	fs.StringVar(&config_path, "config", "", "The path to an optional picturebook config file, encoded as TOML, JSON or YAML, defining default values for picturebook jobs. Values in a job override values in the config file.")
+	fs.StringVar(&config_profile, "profile", "", "The name of a profile, defined in the file specified by the -config flag, whose values should be applied to picturebook jobs.")
+
+	fs.Var(&allow_uris, "allow-uri", "Zero or more bucket URIs (or local paths) below which picturebook jobs may read images and spec files from, or write picturebooks to. Jobs may always use the default source and target buckets, as defined by the -config flag, but any other bucket, output, spec file or (local) source must be below one of these URIs.")
+
+	return fs, nil
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+)
+
+// indexHandler returns an `http.Handler` instance serving a form for submitting jobs and reporting their progress.
+func indexHandler() (http.Handler, error) {
+
+	t, err := template.New("index").Parse(index_template)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse index template, %w", err)
+	}
+
+	fn := func(rsp http.ResponseWriter, req *http.Request) {
+
+		rsp.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		err := t.Execute(rsp, nil)
+
+		if err != nil {
+			slog.Error("Failed to render index", "error", err)
+		}
+	}
+
+	return http.HandlerFunc(fn), nil
+}
+
+// jobsHandler returns an `http.Handler` instance listing every job submitted to 'q'.
+func jobsHandler(q *JobQueue) http.Handler {
+
+	fn := func(rsp http.ResponseWriter, req *http.Request) {
+		writeJSON(rsp, http.StatusOK, q.Jobs())
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// submitHandler returns an `http.Handler` instance adding the JSON-encoded job in the request body to 'q'. Requests whose
+// content type is not "application/json" are rejected.
+func submitHandler(q *JobQueue) http.Handler {
+
+	fn := func(rsp http.ResponseWriter, req *http.Request) {
+
+		ctx := req.Context()
+
+		// Requiring a JSON content type prevents jobs being submitted by cross-site HTML forms
+
+		media_type, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+
+		if err != nil || media_type != "application/json" {
+			http.Error(rsp, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		job, err := q.Submit(ctx, io.LimitReader(req.Body, 1024*1024))
+
+		if errors.Is(err, ErrQueueFull) {
+			http.Error(rsp, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		if errors.Is(err, ErrNotAllowed) {
+			http.Error(rsp, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		if err != nil {
+			http.Error(rsp, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rsp.Header().Set("Location", fmt.Sprintf("/jobs/%s", job.ID))
+		writeJSON(rsp, http.StatusAccepted, job)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// jobHandler returns an `http.Handler` instance reporting the status of a job in 'q'.
+func jobHandler(q *JobQueue) http.Handler {
+
+	fn := func(rsp http.ResponseWriter, req *http.Request) {
+
+		job, exists := q.Job(req.PathValue("id"))
+
+		if !exists {
+			http.Error(rsp, "Not found", http.StatusNotFound)
+			return
+		}
+
+		writeJSON(rsp, http.StatusOK, job)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// downloadHandler returns an `http.Handler` instance serving the picturebook created by a completed job in 'q' from its target bucket.
+func downloadHandler(q *JobQueue) http.Handler {
+
+	fn := func(rsp http.ResponseWriter, req *http.Request) {
+
+		ctx := req.Context()
+
+		job, exists := q.Job(req.PathValue("id"))
+
+		if !exists {
+			http.Error(rsp, "Not found", http.StatusNotFound)
+			return
+		}
+
+		if job.Status != JOB_STATUS_COMPLETE {
+			http.Error(rsp, "Job is not complete", http.StatusConflict)
+			return
+		}
+
+		ext := filepath.Ext(job.Filename)
+
+		if job.Options.Debug || ext == "" {
+			http.Error(rsp, "Job did not create a single picturebook file", http.StatusNotFound)
+			return
+		}
+
+		target, err := job.Options.NewTargetBucket(ctx)
+
+		if err != nil {
+			slog.Error("Failed to open target bucket", "id", job.ID, "error", err)
+			http.Error(rsp, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		defer target.Close()
+
+		r, err := target.NewReader(ctx, job.Filename, nil)
+
+		if err != nil {
+			slog.Error("Failed to open picturebook", "id", job.ID, "filename", job.Filename, "error", err)
+			http.Error(rsp, "Not found", http.StatusNotFound)
+			return
+		}
+
+		defer r.Close()
+
+		content_type := mime.TypeByExtension(ext)
+
+		if content_type == "" {
+			content_type = "application/octet-stream"
+		}
+
+		rsp.Header().Set("Content-Type", content_type)
+		rsp.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filepath.Base(job.Filename)}))
+
+		_, err = io.Copy(rsp, r)
+
+		if err != nil {
+			slog.Error("Failed to serve picturebook", "id", job.ID, "error", err)
+		}
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// writeJSON writes 'v' as a JSON-encoded response with status code 'status'.
+func writeJSON(rsp http.ResponseWriter, status int, v any) {
+
+	rsp.Header().Set("Content-Type", "application/json")
+	rsp.WriteHeader(status)
+
+	enc := json.NewEncoder(rsp)
+	err := enc.Encode(v)
+
+	if err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
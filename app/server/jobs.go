@@ -0,0 +1,349 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aaronland/go-picturebook/app/picturebook"
+	"github.com/aaronland/go-picturebook/progress"
+	"github.com/google/uuid"
+)
+
+// JOB_STATUS_QUEUED is the status of a job waiting to be run.
+const JOB_STATUS_QUEUED string = "queued"
+
+// JOB_STATUS_RUNNING is the status of a job being run.
+const JOB_STATUS_RUNNING string = "running"
+
+// JOB_STATUS_COMPLETE is the status of a job whose picturebook has been created.
+const JOB_STATUS_COMPLETE string = "complete"
+
+// JOB_STATUS_FAILED is the status of a job whose picturebook could not be created.
+const JOB_STATUS_FAILED string = "failed"
+
+// ErrQueueFull is returned by `JobQueue.Submit` when there are already the maximum number of jobs waiting to be run.
+var ErrQueueFull = errors.New("Job queue is full")
+
+// type RunFunc defines a function for creating a picturebook described by a `picturebook.RunOptions` instance.
+type RunFunc func(context.Context, *picturebook.RunOptions) error
+
+// type Job defines a struct containing details about a picturebook being created by a `JobQueue` instance.
+type Job struct {
+	// The unique identifier for the job.
+	ID string `json:"id"`
+	// The status of the job. One of `JOB_STATUS_QUEUED`, `JOB_STATUS_RUNNING`, `JOB_STATUS_COMPLETE` or `JOB_STATUS_FAILED`.
+	Status string `json:"status"`
+	// The options used to create the picturebook.
+	Options *picturebook.RunOptions `json:"options"`
+	// The filename of the finished picturebook in the target bucket.
+	Filename string `json:"filename"`
+	// The most recent progress event signaled while creating the picturebook.
+	Progress *progress.Event `json:"progress,omitempty"`
+	// The error message, if any, reported by a failed job.
+	Error string `json:"error,omitempty"`
+	// The time the job was submitted.
+	Created time.Time `json:"created"`
+	// The time the job started running.
+	Started *time.Time `json:"started,omitempty"`
+	// The time the job stopped running.
+	Completed *time.Time `json:"completed,omitempty"`
+	mu        *sync.RWMutex
+}
+
+// Snapshot returns a copy of 'j' which is safe to read while the job is running.
+func (j *Job) Snapshot() *Job {
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	c := *j
+
+	if j.Progress != nil {
+		ev := *j.Progress
+		c.Progress = &ev
+	}
+
+	return &c
+}
+
+func (j *Job) setStatus(status string, err error) {
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+
+	j.Status = status
+
+	switch status {
+	case JOB_STATUS_RUNNING:
+		j.Started = &now
+	case JOB_STATUS_COMPLETE, JOB_STATUS_FAILED:
+		j.Completed = &now
+	}
+
+	if err != nil {
+		j.Error = err.Error()
+	}
+}
+
+func (j *Job) setProgress(ev *progress.Event) {
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	c := *ev
+	j.Progress = &c
+}
+
+// type JobQueueOptions defines a struct containing configuration details for a `JobQueue` instance.
+type JobQueueOptions struct {
+	// The maximum number of jobs to run concurrently.
+	Workers int
+	// The maximum number of jobs waiting to be run.
+	Size int
+	// The default options for each job. Job descriptions submitted to the queue are applied on top of these values.
+	Defaults *picturebook.RunOptions
+	// The function used to run each job. If nil `picturebook.RunWithOptions` is used.
+	RunFunc RunFunc
+	// Zero or more URIs (or local paths) below which jobs may read from, or write to, locations other than the default
+	// options' buckets. The default options' source and target buckets are always allowed.
+	AllowedURIs []string
+}
+
+// type JobQueue defines a struct for running picturebook jobs, in the order they were submitted, with a bounded number of workers.
+type JobQueue struct {
+	defaults *picturebook.RunOptions
+	run_func RunFunc
+	roots    []string
+	queue    chan *Job
+	jobs     map[string]*Job
+	order    []string
+	mu       *sync.RWMutex
+}
+
+// NewJobQueue returns a new `JobQueue` instance configured by 'opts'. Workers run jobs until 'ctx' is cancelled.
+func NewJobQueue(ctx context.Context, opts *JobQueueOptions) (*JobQueue, error) {
+
+	if opts.Workers < 1 {
+		return nil, fmt.Errorf("Invalid number of workers")
+	}
+
+	if opts.Size < 1 {
+		return nil, fmt.Errorf("Invalid queue size")
+	}
+
+	if opts.Defaults == nil {
+		return nil, fmt.Errorf("Missing default options")
+	}
+
+	run_func := opts.RunFunc
+
+	if run_func == nil {
+		run_func = picturebook.RunWithOptions
+	}
+
+	roots := slices.Clone(opts.AllowedURIs)
+
+	for _, uri := range []string{opts.Defaults.SourceBucketURI, opts.Defaults.TargetBucketURI} {
+
+		if uri != "" {
+			roots = append(roots, uri)
+		}
+	}
+
+	q := &JobQueue{
+		defaults: opts.Defaults,
+		run_func: run_func,
+		roots:    roots,
+		queue:    make(chan *Job, opts.Size),
+		jobs:     make(map[string]*Job),
+		order:    make([]string, 0),
+		mu:       new(sync.RWMutex),
+	}
+
+	for i := 0; i < opts.Workers; i++ {
+		go q.work(ctx)
+	}
+
+	return q, nil
+}
+
+// Submit adds a new job, described by the JSON-encoded `picturebook.RunOptions` in 'r', to the queue. Properties
+// missing from 'r' are assigned the queue's default values. If the job reads from, or writes to, a location it is not
+// allowed to `ErrNotAllowed` is returned. If the queue is full `ErrQueueFull` is returned.
+func (q *JobQueue) Submit(ctx context.Context, r io.Reader) (*Job, error) {
+
+	// Ensure that decoding 'r' does not modify the default values shared by all jobs
+
+	opts := q.defaults.Clone()
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(opts)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode job, %w", err)
+	}
+
+	if opts.Filename == picturebook.STDOUT {
+		return nil, fmt.Errorf("Jobs can not write picturebooks to STDOUT")
+	}
+
+	if opts.Batch != "" {
+		return nil, fmt.Errorf("Jobs can not define a batch file")
+	}
+
+	if opts.Watch {
+		return nil, fmt.Errorf("Jobs can not watch sources for changes")
+	}
+
+	// Verbose logging is enabled for the whole process, and every other job, rather than a single job
+
+	if opts.Verbose && !q.defaults.Verbose {
+		return nil, fmt.Errorf("Jobs can not enable verbose logging")
+	}
+
+	if len(opts.Sources) == 0 && opts.Spec == "" {
+		return nil, fmt.Errorf("Jobs must define one or more sources or a spec file")
+	}
+
+	err = q.ensureAllowed(ctx, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filename, err := opts.DocumentFilename(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to derive filename for job, %w", err)
+	}
+
+	id, err := uuid.NewRandom()
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate job ID, %w", err)
+	}
+
+	job := &Job{
+		ID:       id.String(),
+		Status:   JOB_STATUS_QUEUED,
+		Options:  opts,
+		Filename: filename,
+		Created:  time.Now(),
+		mu:       new(sync.RWMutex),
+	}
+
+	opts.Monitor = &jobMonitor{
+		job: job,
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case q.queue <- job:
+		q.jobs[job.ID] = job
+		q.order = append(q.order, job.ID)
+	default:
+		return nil, ErrQueueFull
+	}
+
+	slog.Info("Job submitted", "id", job.ID, "filename", job.Filename)
+	return job.Snapshot(), nil
+}
+
+// Job returns a snapshot of the job identified by 'id'.
+func (q *JobQueue) Job(id string) (*Job, bool) {
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	job, exists := q.jobs[id]
+
+	if !exists {
+		return nil, false
+	}
+
+	return job.Snapshot(), true
+}
+
+// Jobs returns a snapshot of every job submitted to the queue, in the order they were submitted.
+func (q *JobQueue) Jobs() []*Job {
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	jobs := make([]*Job, len(q.order))
+
+	for i, id := range q.order {
+		jobs[i] = q.jobs[id].Snapshot()
+	}
+
+	return jobs
+}
+
+// work runs jobs from the queue until 'ctx' is cancelled.
+func (q *JobQueue) work(ctx context.Context) {
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-q.queue:
+			q.run(ctx, job)
+		}
+	}
+}
+
+// run runs 'job' recording its status before and after.
+func (q *JobQueue) run(ctx context.Context, job *Job) {
+
+	logger := slog.Default()
+	logger = logger.With("id", job.ID)
+
+	logger.Info("Job started")
+	job.setStatus(JOB_STATUS_RUNNING, nil)
+
+	err := q.run_func(ctx, job.Options)
+
+	if err != nil {
+		logger.Error("Job failed", "error", err)
+		job.setStatus(JOB_STATUS_FAILED, err)
+		return
+	}
+
+	logger.Info("Job complete")
+	job.setStatus(JOB_STATUS_COMPLETE, nil)
+}
+
+// jobMonitor implements the `progress.Monitor` interface recording each event as the progress of a `Job`.
+type jobMonitor struct {
+	progress.Monitor
+	job *Job
+}
+
+// Signal records 'ev' as the job's current progress.
+func (m *jobMonitor) Signal(ctx context.Context, ev *progress.Event) error {
+	m.job.setProgress(ev)
+	return nil
+}
+
+// Clear is a no-op. The most recent event is kept so it can be reported once the job has finished.
+func (m *jobMonitor) Clear() error {
+	return nil
+}
+
+// Close is a no-op.
+func (m *jobMonitor) Close() error {
+	return nil
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"flag"
+
+	"github.com/sfomuseum/go-flags/flagset"
+)
+
+// RunOptions is a struct containing details about running the `picturebook-server` application.
+type RunOptions struct {
+	// The URI of the HTTP server to listen for requests on.
+	ServerURI string
+	// The maximum number of picturebook jobs to run concurrently.
+	Workers int
+	// The maximum number of picturebook jobs waiting to be run.
+	QueueSize int
+	// The path to an optional picturebook config file defining default values for picturebook jobs.
+	Config string
+	// The name of a profile, defined in `Config`, whose values are applied to picturebook jobs.
+	Profile string
+	// Zero or more URIs (or local paths) below which picturebook jobs may read from, or write to, locations other than the default source and target buckets.
+	AllowedURIs []string
+}
+
+// Derive a new `RunOptions` instances from 'fs'.
+func RunOptionsFromFlagSet(ctx context.Context, fs *flag.FlagSet) (*RunOptions, error) {
+
+	flagset.Parse(fs)
+
+	opts := &RunOptions{
+		ServerURI:   server_uri,
+		Workers:     workers,
+		QueueSize:   queue_size,
+		Config:      config_path,
+		Profile:     config_profile,
+		AllowedURIs: allow_uris,
+	}
+
+	return opts, nil
+}
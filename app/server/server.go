@@ -0,0 +1,193 @@
+// package server provides an HTTP application for creating picturebooks using a queue of jobs.
+package server
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aaronland/go-picturebook/app/picturebook"
+	"github.com/aaronland/go-picturebook/bucket"
+)
+
+// READ_HEADER_TIMEOUT is the maximum amount of time the server waits to read the headers of a request.
+const READ_HEADER_TIMEOUT time.Duration = 10 * time.Second
+
+// READ_TIMEOUT is the maximum amount of time the server waits to read an entire request, including the body.
+const READ_TIMEOUT time.Duration = 30 * time.Second
+
+// IDLE_TIMEOUT is the maximum amount of time the server keeps an idle (keep-alive) connection open.
+const IDLE_TIMEOUT time.Duration = 120 * time.Second
+
+// SHUTDOWN_TIMEOUT is the maximum amount of time the server waits for active requests to complete when it is shut down.
+const SHUTDOWN_TIMEOUT time.Duration = 30 * time.Second
+
+// Run will run the `picturebook-server` application configured using the default flagset and options.
+func Run(ctx context.Context) error {
+
+	fs, err := DefaultFlagSet(ctx)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create default flag set, %w", err)
+	}
+
+	return RunWithFlagSet(ctx, fs)
+}
+
+// Run will run the `picturebook-server` application configured using 'fs'.
+func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
+
+	opts, err := RunOptionsFromFlagSet(ctx, fs)
+
+	if err != nil {
+		return err
+	}
+
+	return RunWithOptions(ctx, opts)
+}
+
+// Run will run the `picturebook-server` application configured using 'opts'. The server is shut down gracefully when 'ctx'
+// is cancelled or the process receives an interrupt or SIGTERM signal.
+func RunWithOptions(ctx context.Context, opts *RunOptions) error {
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		return fmt.Errorf("Failed to register gocloud buckets, %w", err)
+	}
+
+	u, err := url.Parse(opts.ServerURI)
+
+	if err != nil {
+		return fmt.Errorf("Failed to parse server URI, %w", err)
+	}
+
+	if u.Scheme != "http" {
+		return fmt.Errorf("Unsupported server URI scheme '%s'", u.Scheme)
+	}
+
+	// Default job options are derived from the picturebook application's flags (and any config file) so that
+	// jobs only need to specify the values they want to change.
+
+	args := make([]string, 0)
+
+	if opts.Config != "" {
+		args = append(args, "-config", opts.Config)
+	}
+
+	if opts.Profile != "" {
+		args = append(args, "-profile", opts.Profile)
+	}
+
+	defaults, err := picturebook.RunOptionsFromArgs(ctx, args)
+
+	if err != nil {
+		return fmt.Errorf("Failed to derive default job options, %w", err)
+	}
+
+	q_opts := &JobQueueOptions{
+		Workers:     opts.Workers,
+		Size:        opts.QueueSize,
+		Defaults:    defaults,
+		AllowedURIs: opts.AllowedURIs,
+	}
+
+	q, err := NewJobQueue(ctx, q_opts)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create job queue, %w", err)
+	}
+
+	mux, err := NewServeMux(ctx, q)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create handlers, %w", err)
+	}
+
+	ln, err := net.Listen("tcp", u.Host)
+
+	if err != nil {
+		return fmt.Errorf("Failed to listen on %s, %w", u.Host, err)
+	}
+
+	s := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: READ_HEADER_TIMEOUT,
+		ReadTimeout:       READ_TIMEOUT,
+		IdleTimeout:       IDLE_TIMEOUT,
+	}
+
+	slog.Info("Listening for requests", "address", opts.ServerURI)
+
+	return serve(ctx, s, ln)
+}
+
+// serve serves requests for 's' on 'ln' until 'ctx' is cancelled. The server is then shut down, waiting up to `SHUTDOWN_TIMEOUT`
+// for active requests to complete.
+func serve(ctx context.Context, s *http.Server, ln net.Listener) error {
+
+	serve_err := make(chan error, 1)
+
+	go func() {
+		serve_err <- s.Serve(ln)
+	}()
+
+	select {
+	case err := <-serve_err:
+		return fmt.Errorf("Failed to serve requests, %w", err)
+	case <-ctx.Done():
+		// pass
+	}
+
+	slog.Info("Shutting down server")
+
+	shutdown_ctx, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+	defer cancel()
+
+	err := s.Shutdown(shutdown_ctx)
+
+	if err != nil {
+		return fmt.Errorf("Failed to shut down server, %w", err)
+	}
+
+	err = <-serve_err
+
+	if !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("Failed to serve requests, %w", err)
+	}
+
+	return nil
+}
+
+// NewServeMux returns a new `http.ServeMux` instance with handlers for submitting jobs to 'q', reporting their status
+// and serving the finished picturebooks.
+func NewServeMux(ctx context.Context, q *JobQueue) (*http.ServeMux, error) {
+
+	index_handler, err := indexHandler()
+
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+
+	mux.Handle("GET /{$}", index_handler)
+	mux.Handle("GET /jobs", jobsHandler(q))
+	mux.Handle("POST /jobs", submitHandler(q))
+	mux.Handle("GET /jobs/{id}", jobHandler(q))
+	mux.Handle("GET /jobs/{id}/download", downloadHandler(q))
+
+	return mux, nil
+}
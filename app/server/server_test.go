@@ -0,0 +1,291 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aaronland/go-picturebook/app/picturebook"
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/progress"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestServer(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to register buckets, %v", err)
+	}
+
+	allowed_root := t.TempDir()
+
+	defaults := &picturebook.RunOptions{
+		SourceBucketURI: fmt.Sprintf("file://%s", t.TempDir()),
+		TargetBucketURI: fmt.Sprintf("file://%s", t.TempDir()),
+		Filename:        "picturebook.pdf",
+	}
+
+	// Write a placeholder document rather than creating an actual picturebook
+
+	run_func := func(ctx context.Context, opts *picturebook.RunOptions) error {
+
+		opts.Monitor.Signal(ctx, progress.NewEvent(1, 1))
+
+		target, err := opts.NewTargetBucket(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		wr, err := target.NewWriter(ctx, opts.Filename, nil)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = wr.Write([]byte(strings.Join(opts.Sources, ",")))
+
+		if err != nil {
+			return err
+		}
+
+		return wr.Close()
+	}
+
+	q, err := NewJobQueue(ctx, &JobQueueOptions{
+		Workers:     1,
+		Size:        1,
+		Defaults:    defaults,
+		RunFunc:     run_func,
+		AllowedURIs: []string{allowed_root},
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to create job queue, %v", err)
+	}
+
+	mux, err := NewServeMux(ctx, q)
+
+	if err != nil {
+		t.Fatalf("Failed to create serve mux, %v", err)
+	}
+
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	rsp, err := http.Post(s.URL+"/jobs", "application/json", strings.NewReader(`{"Filename": "test.pdf"}`))
+
+	if err != nil {
+		t.Fatalf("Failed to submit job, %v", err)
+	}
+
+	rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected job without sources to be rejected, got %d", rsp.StatusCode)
+	}
+
+	rsp, err = http.Post(s.URL+"/jobs", "application/json", strings.NewReader(`{"Sources": ["a"], "Verbose": true}`))
+
+	if err != nil {
+		t.Fatalf("Failed to submit job, %v", err)
+	}
+
+	rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected job enabling verbose logging to be rejected, got %d", rsp.StatusCode)
+	}
+
+	// Jobs can not be submitted by cross-site HTML forms
+
+	rsp, err = http.Post(s.URL+"/jobs", "application/x-www-form-urlencoded", strings.NewReader(`{"Sources": ["a"]}`))
+
+	if err != nil {
+		t.Fatalf("Failed to submit job, %v", err)
+	}
+
+	rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("Expected job with form content type to be rejected, got %d", rsp.StatusCode)
+	}
+
+	// Jobs can only read from, or write to, the default buckets or locations below the allowed roots
+
+	spec_path := filepath.Join(allowed_root, "book.yaml")
+
+	err = os.WriteFile(spec_path, []byte(`pages: [{type: picture, path: /etc/secret.jpg}]`), 0644)
+
+	if err != nil {
+		t.Fatalf("Failed to write spec, %v", err)
+	}
+
+	not_allowed := []string{
+		`{"Sources": ["a"], "TargetBucketURI": "file:///tmp"}`,
+		fmt.Sprintf(`{"Sources": ["a"], "TargetBucketURI": "file://%s/../elsewhere"}`, allowed_root),
+		fmt.Sprintf(`{"Sources": ["a"], "CacheBucketURI": "file://%s?secret_key_path=/etc/passwd"}`, allowed_root),
+		`{"Sources": ["a"], "Spec": "/etc/book.yaml"}`,
+		`{"Sources": ["../a"]}`,
+		`{"Sources": ["a"], "Filename": "../test.pdf"}`,
+		`{"Sources": ["/etc"], "SourceBucketURI": ""}`,
+		fmt.Sprintf(`{"Spec": "%s", "SourceBucketURI": ""}`, spec_path),
+	}
+
+	for _, body := range not_allowed {
+
+		rsp, err = http.Post(s.URL+"/jobs", "application/json", strings.NewReader(body))
+
+		if err != nil {
+			t.Fatalf("Failed to submit job, %v", err)
+		}
+
+		rsp.Body.Close()
+
+		if rsp.StatusCode != http.StatusForbidden {
+			t.Fatalf("Expected job %s to be rejected, got %d", body, rsp.StatusCode)
+		}
+	}
+
+	local_opts := defaults.Clone()
+	local_opts.SourceBucketURI = ""
+	local_opts.Sources = []string{allowed_root + "/images"}
+
+	target_opts := defaults.Clone()
+	target_opts.Sources = []string{"a"}
+	target_opts.TargetBucketURI = allowed_root + "/books"
+
+	for _, opts := range []*picturebook.RunOptions{local_opts, target_opts} {
+
+		err := q.ensureAllowed(ctx, opts)
+
+		if err != nil {
+			t.Fatalf("Expected job to be allowed, %v", err)
+		}
+	}
+
+	rsp, err = http.Post(s.URL+"/jobs", "application/json", strings.NewReader(`{"Sources": ["a", "b"], "Filename": "test.pdf"}`))
+
+	if err != nil {
+		t.Fatalf("Failed to submit job, %v", err)
+	}
+
+	if rsp.StatusCode != http.StatusAccepted {
+		t.Fatalf("Unexpected status code submitting job: %d", rsp.StatusCode)
+	}
+
+	var job *Job
+
+	err = json.NewDecoder(rsp.Body).Decode(&job)
+	rsp.Body.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to decode job, %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+
+		status, exists := q.Job(job.ID)
+
+		if !exists {
+			t.Fatalf("Job %s not found", job.ID)
+		}
+
+		job = status
+
+		if job.Status == JOB_STATUS_COMPLETE || job.Status == JOB_STATUS_FAILED {
+			break
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if job.Status != JOB_STATUS_COMPLETE {
+		t.Fatalf("Unexpected job status '%s' (%s)", job.Status, job.Error)
+	}
+
+	if job.Progress == nil || job.Progress.Page != 1 {
+		t.Fatalf("Job progress was not recorded")
+	}
+
+	rsp, err = http.Get(fmt.Sprintf("%s/jobs/%s/download", s.URL, job.ID))
+
+	if err != nil {
+		t.Fatalf("Failed to download picturebook, %v", err)
+	}
+
+	defer rsp.Body.Close()
+
+	body, err := io.ReadAll(rsp.Body)
+
+	if err != nil {
+		t.Fatalf("Failed to read picturebook, %v", err)
+	}
+
+	if rsp.StatusCode != http.StatusOK || string(body) != "a,b" {
+		t.Fatalf("Unexpected download response %d '%s'", rsp.StatusCode, body)
+	}
+
+	if rsp.Header.Get("Content-Type") != "application/pdf" {
+		t.Fatalf("Unexpected content type '%s'", rsp.Header.Get("Content-Type"))
+	}
+}
+
+func TestServe(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ln, err := net.Listen("tcp", "localhost:0")
+
+	if err != nil {
+		t.Fatalf("Failed to listen, %v", err)
+	}
+
+	s := &http.Server{
+		Handler: http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+			rsp.Write([]byte("ok"))
+		}),
+		ReadHeaderTimeout: READ_HEADER_TIMEOUT,
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- serve(ctx, s, ln)
+	}()
+
+	rsp, err := http.Get(fmt.Sprintf("http://%s/", ln.Addr().String()))
+
+	if err != nil {
+		t.Fatalf("Failed to execute request, %v", err)
+	}
+
+	rsp.Body.Close()
+
+	cancel()
+
+	select {
+	case err := <-done:
+
+		if err != nil {
+			t.Fatalf("Expected server to shut down cleanly, %v", err)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Server did not shut down")
+	}
+}
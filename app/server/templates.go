@@ -0,0 +1,128 @@
+package server
+
+// index_template is the HTML form used to submit jobs and report their progress. Values not included in the form
+// are assigned the server's default job options.
+const index_template string = `<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="utf-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1" />
+    <title>picturebook</title>
+    <style type="text/css">
+      body { font-family: sans-serif; margin: 2em auto; max-width: 40em; padding: 0 1em; }
+      label { display: block; font-weight: bold; margin-top: 1em; }
+      input[type=text], textarea, select { box-sizing: border-box; width: 100%; }
+      button { margin-top: 1.5em; }
+      #status { margin-top: 2em; }
+      .error { color: #c00; }
+    </style>
+  </head>
+  <body>
+    <h1>picturebook</h1>
+    <form id="job">
+      <label for="sources">Folders</label>
+      <textarea id="sources" rows="3" placeholder="One folder per line" required></textarea>
+      <label for="filename">Filename</label>
+      <input type="text" id="filename" value="picturebook.pdf" required />
+      <label for="title">Title</label>
+      <input type="text" id="title" />
+      <label for="size">Page size</label>
+      <select id="size">
+        <option value="letter">Letter</option>
+        <option value="legal">Legal</option>
+        <option value="tabloid">Tabloid</option>
+        <option value="a3">A3</option>
+        <option value="a4">A4</option>
+        <option value="a5">A5</option>
+      </select>
+      <label for="orientation">Orientation</label>
+      <select id="orientation">
+        <option value="P">Portrait</option>
+        <option value="L">Landscape</option>
+      </select>
+      <label for="output">Format</label>
+      <select id="output">
+        <option value="">PDF</option>
+        <option value="epub://">EPUB</option>
+        <option value="cbz://">Comic book archive (CBZ)</option>
+      </select>
+      <label><input type="checkbox" id="fill-page" /> Rotate images to fill each page</label>
+      <button type="submit">Create picturebook</button>
+    </form>
+    <div id="status"></div>
+    <script type="text/javascript">
+      const form = document.getElementById("job");
+      const status = document.getElementById("status");
+
+      const show = function(msg, is_error){
+          status.textContent = msg;
+          status.className = (is_error) ? "error" : "";
+      };
+
+      const poll = function(id){
+
+          fetch("/jobs/" + id).then((rsp) => rsp.json()).then((job) => {
+
+              if (job.status == "complete"){
+                  status.textContent = "";
+                  const a = document.createElement("a");
+                  a.href = "/jobs/" + id + "/download";
+                  a.textContent = "Download " + job.filename;
+                  status.appendChild(a);
+                  return;
+              }
+
+              if (job.status == "failed"){
+                  show("Failed to create picturebook: " + job.error, true);
+                  return;
+              }
+
+              let msg = "Job is " + job.status;
+
+              if (job.progress && job.progress.pages > 0){
+                  msg += " (" + job.progress.stage + ": " + job.progress.page + " of " + job.progress.pages + ")";
+              } else if (job.progress){
+                  msg += " (" + job.progress.stage + ": " + job.progress.page + ")";
+              }
+
+              show(msg, false);
+              setTimeout(() => poll(id), 1000);
+
+          }).catch((err) => show("Failed to retrieve job status: " + err, true));
+      };
+
+      form.onsubmit = function(e){
+
+          e.preventDefault();
+
+          const sources = document.getElementById("sources").value.split("\n").map((s) => s.trim()).filter((s) => s != "");
+
+          const job = {
+              Sources: sources,
+              Filename: document.getElementById("filename").value,
+              Title: document.getElementById("title").value,
+              Size: document.getElementById("size").value,
+              Orientation: document.getElementById("orientation").value,
+              OutputURI: document.getElementById("output").value,
+              FillPage: document.getElementById("fill-page").checked,
+          };
+
+          fetch("/jobs", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify(job) }).then((rsp) => {
+
+              if (! rsp.ok){
+                  return rsp.text().then((txt) => { throw new Error(txt) });
+              }
+
+              return rsp.json();
+
+          }).then((job) => {
+              show("Job is " + job.status, false);
+              poll(job.id);
+          }).catch((err) => show("Failed to submit job: " + err.message, true));
+
+          return false;
+      };
+    </script>
+  </body>
+</html>
+`
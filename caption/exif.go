@@ -6,6 +6,7 @@ import (
 	"net/url"
 
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 	"github.com/rwcarlsen/goexif/exif"
 	"github.com/rwcarlsen/goexif/mknote"
 )
@@ -19,6 +20,29 @@ func init() {
 		panic(err)
 	}
 
+	err = handler.RegisterDescription(ctx, handler.CAPTION, &handler.Description{
+		Scheme:  "exif",
+		Summary: "Derive captions from an EXIF property of each image. If EXIF data is not present, or can not be loaded, the caption is empty.",
+		Syntax:  "exif://?property={PROPERTY}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "property",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "string",
+				Description: "The EXIF property to derive captions from.",
+				Required:    true,
+				Values:      []string{"datetime"},
+			},
+		},
+		Examples: []string{
+			"exif://?property=datetime",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
 	exif.RegisterParsers(mknote.All...)
 }
 
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 )
 
 func init() {
@@ -17,6 +18,19 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.CAPTION, &handler.Description{
+		Scheme:  "filename",
+		Summary: "Derive captions from the filename of each image.",
+		Syntax:  "filename://",
+		Examples: []string{
+			"filename://",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // type FilenameCaption implements the `Caption` interface and derives caption text from image filenames.
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 )
 
 func init() {
@@ -18,6 +19,28 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.CAPTION, &handler.Description{
+		Scheme:  "json",
+		Summary: "Derive captions from a JSON file, on the local disk, mapping the path of each image to its caption.",
+		Syntax:  "json://{PATH}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "path",
+				Location:    handler.PARAMETER_PATH,
+				Type:        "path",
+				Description: "The absolute path to a JSON file containing a dictionary whose keys are image paths and whose values are captions.",
+				Required:    true,
+			},
+		},
+		Examples: []string{
+			"json:///path/to/captions.json",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // type JsonCaption implements the `Caption` interface and returns empty caption strings.
@@ -6,6 +6,7 @@ import (
 	"net/url"
 
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 )
 
 func init() {
@@ -16,6 +17,19 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.CAPTION, &handler.Description{
+		Scheme:  "modtime",
+		Summary: "Derive captions from the modification time of each image.",
+		Syntax:  "modtime://",
+		Examples: []string{
+			"modtime://",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // type ModtimeCaption implements the `Caption` interface and derives caption text from image modification times.
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 )
 
 func init() {
@@ -17,6 +18,28 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.CAPTION, &handler.Description{
+		Scheme:  "multi",
+		Summary: "Derive captions from the first of one or more caption URIs to return a non-empty caption.",
+		Syntax:  "multi://?uri={URI}&uri={URI}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "uri",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "uri",
+				Description: "A caption URI. This parameter may be repeated and URIs are tested in order.",
+				Required:    true,
+			},
+		},
+		Examples: []string{
+			"multi://?uri=exif://?property=datetime&uri=filename://",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 type MultiCaptionOptions struct {
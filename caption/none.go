@@ -6,6 +6,7 @@ import (
 	"net/url"
 
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 )
 
 func init() {
@@ -16,6 +17,19 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.CAPTION, &handler.Description{
+		Scheme:  "none",
+		Summary: "Assign an empty caption to every image.",
+		Syntax:  "none://",
+		Examples: []string{
+			"none://",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // type ExifCaption implements the `Caption` interface and returns empty caption strings.
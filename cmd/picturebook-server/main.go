@@ -0,0 +1,20 @@
+// picturebook-server is an HTTP application for creating picturebooks, from a queue of jobs, using a web browser or a JSON API.
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/aaronland/go-picturebook/app/server"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func main() {
+
+	ctx := context.Background()
+	err := server.Run(ctx)
+
+	if err != nil {
+		log.Fatalf("Failed to run picturebook-server application, %v", err)
+	}
+}
@@ -4,6 +4,7 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 
 	"github.com/aaronland/go-picturebook/app/picturebook"
 	_ "gocloud.dev/blob/fileblob"
@@ -12,7 +13,23 @@ import (
 func main() {
 
 	ctx := context.Background()
-	err := picturebook.Run(ctx)
+
+	var err error
+
+	subcommand := ""
+
+	if len(os.Args) > 1 {
+		subcommand = os.Args[1]
+	}
+
+	switch subcommand {
+	case picturebook.INSPECT_SUBCOMMAND:
+		err = picturebook.RunInspect(ctx, os.Args[2:])
+	case picturebook.HANDLERS_SUBCOMMAND:
+		err = picturebook.RunHandlers(ctx, os.Args[2:])
+	default:
+		err = picturebook.Run(ctx)
+	}
 
 	if err != nil {
 		log.Fatalf("Failed to run picturebook application, %v", err)
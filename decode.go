@@ -0,0 +1,125 @@
+package picturebook
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+
+	"github.com/aaronland/go-image/v2/decode"
+)
+
+// pngSignature is the 8-byte signature that every PNG file starts with.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// imageConfig defines a struct containing the details derived from reading the header of an image.
+type imageConfig struct {
+	// The width of the image in pixels.
+	Width int
+	// The height of the image in pixels.
+	Height int
+	// The (short) name of the image format, for example "jpeg" or "png".
+	Format string
+	// The colour model of the image, for example `color.CMYKModel`.
+	ColorModel color.Model
+}
+
+// decodeImageConfig reads the header of the image in 'r' and returns its dimensions and format without decoding
+// the image's pixels. 'r' is rewound to the beginning of the file before returning.
+func decodeImageConfig(ctx context.Context, r io.ReadSeeker) (*imageConfig, error) {
+
+	cfg, format, err := image.DecodeConfig(r)
+
+	_, seek_err := r.Seek(0, io.SeekStart)
+
+	if seek_err != nil {
+		return nil, fmt.Errorf("Failed to rewind reader, %w", seek_err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	im_cfg := &imageConfig{
+		Width:      cfg.Width,
+		Height:     cfg.Height,
+		Format:     format,
+		ColorModel: cfg.ColorModel,
+	}
+
+	return im_cfg, nil
+}
+
+// decodeImage fully decodes the image in 'r', having first rewound it to the beginning of the file,
+// returning the image and its (short) format name.
+func decodeImage(ctx context.Context, r io.ReadSeeker) (image.Image, string, error) {
+
+	_, err := r.Seek(0, io.SeekStart)
+
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to rewind reader, %w", err)
+	}
+
+	decode_opts := &decode.DecodeImageOptions{
+		Rotate: false,
+	}
+
+	im, im_format, _, err := decode.DecodeImageWithOptions(ctx, r, decode_opts)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if im == nil {
+		return nil, "", fmt.Errorf("Image decoded but did not return an image object")
+	}
+
+	return im, im_format, nil
+}
+
+// pngRequiresConversion reads the IHDR chunk of the PNG image in 'r' and reports whether the image
+// uses features that fpdf does not support (bit depths greater than 8 or interlacing) and must therefore
+// be converted before being added to a picturebook. 'r' is rewound to the beginning of the file before returning.
+func pngRequiresConversion(r io.ReadSeeker) (bool, error) {
+
+	// 8 byte signature, 4 byte chunk length, 4 byte chunk type ("IHDR"), 4 bytes width, 4 bytes height,
+	// 1 byte bit depth, 1 byte colour type, 1 byte compression method, 1 byte filter method, 1 byte interlace method
+
+	header := make([]byte, 29)
+
+	_, err := io.ReadFull(r, header)
+
+	_, seek_err := r.Seek(0, io.SeekStart)
+
+	if seek_err != nil {
+		return false, fmt.Errorf("Failed to rewind reader, %w", seek_err)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("Failed to read PNG header, %w", err)
+	}
+
+	if !bytes.Equal(header[0:8], pngSignature) {
+		return false, fmt.Errorf("Invalid PNG signature")
+	}
+
+	if string(header[12:16]) != "IHDR" {
+		return false, fmt.Errorf("Missing IHDR chunk")
+	}
+
+	if binary.BigEndian.Uint32(header[8:12]) != 13 {
+		return false, fmt.Errorf("Invalid IHDR chunk length")
+	}
+
+	bit_depth := header[24]
+	interlace := header[28]
+
+	if bit_depth > 8 || interlace != 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,197 @@
+package picturebook
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/aaronland/go-picturebook/picture"
+)
+
+func TestPNGRequiresConversion(t *testing.T) {
+
+	tests := map[string]struct {
+		im       image.Image
+		expected bool
+	}{
+		"8-bit": {
+			im:       image.NewNRGBA(image.Rect(0, 0, 8, 4)),
+			expected: false,
+		},
+		"16-bit": {
+			im:       image.NewNRGBA64(image.Rect(0, 0, 8, 4)),
+			expected: true,
+		},
+	}
+
+	for label, tc := range tests {
+
+		tc.im.(interface{ Set(int, int, color.Color) }).Set(1, 1, color.White)
+
+		buf := new(bytes.Buffer)
+
+		err := png.Encode(buf, tc.im)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s image, %v", label, err)
+		}
+
+		r := bytes.NewReader(buf.Bytes())
+
+		convert, err := pngRequiresConversion(r)
+
+		if err != nil {
+			t.Fatalf("Failed to read %s PNG header, %v", label, err)
+		}
+
+		if convert != tc.expected {
+			t.Fatalf("Unexpected result for %s image: %t", label, convert)
+		}
+
+		cfg, err := decodeImageConfig(context.Background(), r)
+
+		if err != nil {
+			t.Fatalf("Failed to decode %s image config after reading header, %v", label, err)
+		}
+
+		if cfg.Width != 8 || cfg.Height != 4 || cfg.Format != "png" {
+			t.Fatalf("Unexpected config for %s image: %v", label, cfg)
+		}
+	}
+}
+
+func TestAddPictureTruncatedPNG(t *testing.T) {
+
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+
+	err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+
+	if err != nil {
+		t.Fatalf("Failed to encode image, %v", err)
+	}
+
+	// The PNG signature and IHDR chunk are enough to decode the image config, and to pass pngRequiresConversion,
+	// but not for fpdf to read the image data
+
+	root := t.TempDir()
+
+	writeTestFiles(t, root, map[string]string{
+		"good.png":      "",
+		"truncated.png": string(buf.Bytes()[:33]),
+	})
+
+	pb, _ := newTestPictureBook(t, nil)
+
+	err = pb.AddPictures(ctx, []string{root})
+
+	if err != nil {
+		t.Fatalf("Failed to add pictures, %v", err)
+	}
+
+	skipped := pb.SkippedPictures()
+
+	if len(skipped) != 1 || filepath.Base(skipped[0].Path) != "truncated.png" {
+		t.Fatalf("Expected truncated.png to be skipped, %v", skipped)
+	}
+}
+
+func TestAddPictureAPNG(t *testing.T) {
+
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+
+	err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+
+	if err != nil {
+		t.Fatalf("Failed to encode image, %v", err)
+	}
+
+	// Insert an acTL (animation control) chunk after the IHDR chunk so that the image is detected as
+	// "image/vnd.mozilla.apng" rather than "image/png"
+
+	chunk := []byte("acTL")
+	chunk = binary.BigEndian.AppendUint32(chunk, 1)
+	chunk = binary.BigEndian.AppendUint32(chunk, 0)
+
+	actl := binary.BigEndian.AppendUint32(nil, uint32(len(chunk)-4))
+	actl = append(actl, chunk...)
+	actl = binary.BigEndian.AppendUint32(actl, crc32.ChecksumIEEE(chunk))
+
+	body := buf.Bytes()
+
+	apng := append([]byte{}, body[:33]...)
+	apng = append(apng, actl...)
+	apng = append(apng, body[33:]...)
+
+	root := t.TempDir()
+
+	writeTestFiles(t, root, map[string]string{
+		"animated.png": string(apng),
+	})
+
+	pb, _ := newTestPictureBook(t, func(opts *PictureBookOptions) {
+		opts.ErrorPolicy = ERROR_POLICY_STRICT
+	})
+
+	err = pb.AddPictures(ctx, []string{root})
+
+	if err != nil {
+		t.Fatalf("Failed to add APNG image, %v", err)
+	}
+
+	if len(pb.Pages()) != 1 {
+		t.Fatalf("Expected 1 page, got %d", len(pb.Pages()))
+	}
+}
+
+func TestAddPictureUnsupportedFormat(t *testing.T) {
+
+	ctx := context.Background()
+
+	// Register a format which image.DecodeConfig understands, and which passes ensureSupportedFormat because it is an
+	// alias, but which AddPicture does not handle
+
+	decode_config := func(r io.Reader) (image.Config, error) {
+		return image.Config{ColorModel: color.RGBAModel, Width: 40, Height: 20}, nil
+	}
+
+	decode := func(r io.Reader) (image.Image, error) {
+		return image.NewRGBA(image.Rect(0, 0, 40, 20)), nil
+	}
+
+	image.RegisterFormat("vnd.mozilla.apng", "PBTEST", decode, decode_config)
+
+	root := t.TempDir()
+
+	writeTestFiles(t, root, map[string]string{
+		"test.pbt": "PBTEST",
+	})
+
+	pb, _ := newTestPictureBook(t, nil)
+
+	pic := &picture.PictureBookPicture{
+		Source: filepath.Join(root, "test.pbt"),
+		Path:   filepath.Join(root, "test.pbt"),
+	}
+
+	err := pb.AddPicture(ctx, 1, pic)
+
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Expected unsupported format error, %v", err)
+	}
+
+	if len(pb.Pages()) != 0 {
+		t.Fatalf("Expected no pages, got %d", len(pb.Pages()))
+	}
+}
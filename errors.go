@@ -0,0 +1,100 @@
+package picturebook
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// ERROR_POLICY_SKIP is the error policy used to signal that images which can not be added to a picturebook should be skipped,
+// and recorded, and that the picturebook should continue to be created.
+const ERROR_POLICY_SKIP string = "skip"
+
+// ERROR_POLICY_STRICT is the error policy used to signal that the creation of a picturebook should stop as soon as an image
+// can not be added to it.
+const ERROR_POLICY_STRICT string = "strict"
+
+// ErrNotImage is the error returned by `GatherPicturesProcessFunc` functions for files which are not images. Files which are
+// not images are always skipped, regardless of the picturebook's error policy.
+var ErrNotImage = errors.New("File does not appear to be an image")
+
+// ErrUnsupportedFormat is the error returned for images whose format can not be added to a picturebook.
+var ErrUnsupportedFormat = errors.New("Image format is not supported")
+
+// supported_formats is the list of (short) image format names, or image mimetypes without the "image/" prefix, which can be added to a picturebook.
+var supported_formats = []string{
+	"jpeg",
+	"jpg",
+	"gif",
+	"png",
+	"webp",
+	"tiff",
+	"tif",
+	"heic",
+}
+
+// format_aliases maps image mimetypes, without the "image/" prefix, to the entry in `supported_formats` they are a variant of.
+var format_aliases = map[string]string{
+	"vnd.mozilla.apng": "png",
+}
+
+// type SkippedPicture defines a struct containing details about a file which was not added to a picturebook.
+type SkippedPicture struct {
+	// The path of the file, as gathered from the picturebook's sources. This may be empty if the error occurred gathering the file itself.
+	Path string `json:"path"`
+	// The reason the file was not added to the picturebook.
+	Reason string `json:"reason"`
+	// The error which caused the file to be skipped.
+	Error error `json:"-"`
+}
+
+// SkippedPictures returns the list of files which were not added to the picturebook, and why. Files which were skipped while
+// being gathered are listed, in the order they were gathered, before files which were skipped while being added to a page.
+func (pb *PictureBook) SkippedPictures() []*SkippedPicture {
+	return pb.skipped
+}
+
+// skipPicture records that the file at 'path' was not added to the picturebook because of 'err'. It is expected to be called
+// while holding `pb.Mutex`.
+func (pb *PictureBook) skipPicture(path string, err error) {
+	pb.skipped = append(pb.skipped, newSkippedPicture(path, err))
+}
+
+// newSkippedPicture returns a new `SkippedPicture` instance for the file at 'path' which was not added to a picturebook because of 'err'.
+func newSkippedPicture(path string, err error) *SkippedPicture {
+
+	slog.Warn("Skip picture", "path", path, "error", err)
+
+	s := &SkippedPicture{
+		Path:   path,
+		Reason: err.Error(),
+		Error:  err,
+	}
+
+	return s
+}
+
+// strict returns a boolean value signaling whether the picturebook's error policy is `ERROR_POLICY_STRICT`.
+func (pb *PictureBook) strict() bool {
+	return pb.Options.ErrorPolicy == ERROR_POLICY_STRICT
+}
+
+// ensureSupportedFormat returns an error if 'format', which may be a short format name or an image mimetype, can not be added to a picturebook.
+func ensureSupportedFormat(format string) error {
+
+	short_format := strings.TrimPrefix(format, "image/")
+
+	alias, ok := format_aliases[short_format]
+
+	if ok {
+		short_format = alias
+	}
+
+	if !slices.Contains(supported_formats, short_format) {
+		return fmt.Errorf("%w (%s)", ErrUnsupportedFormat, format)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package picturebook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"path/filepath"
+	"testing"
+
+	"github.com/aaronland/go-picturebook/picture"
+)
+
+func TestErrorPolicy(t *testing.T) {
+
+	ctx := context.Background()
+
+	root := t.TempDir()
+
+	// A GIF header without an image and a file which is not an image at all
+
+	writeTestFiles(t, root, map[string]string{
+		"good.png":  "",
+		"bad.gif":   "GIF89a\n",
+		"notes.txt": "notes",
+	})
+
+	pb, _ := newTestPictureBook(t, nil)
+
+	err := pb.AddPictures(ctx, []string{root})
+
+	if err != nil {
+		t.Fatalf("Failed to add pictures, %v", err)
+	}
+
+	if len(pb.Pages()) != 1 {
+		t.Fatalf("Unexpected page count: %d", len(pb.Pages()))
+	}
+
+	skipped := pb.SkippedPictures()
+
+	if len(skipped) != 2 {
+		t.Fatalf("Unexpected skipped pictures: %d", len(skipped))
+	}
+
+	// Files are skipped when they are gathered before files which fail to be added
+
+	if filepath.Base(skipped[0].Path) != "notes.txt" || filepath.Base(skipped[1].Path) != "bad.gif" {
+		t.Fatalf("Unexpected skipped pictures: %s, %s", skipped[0].Path, skipped[1].Path)
+	}
+
+	if !errors.Is(skipped[0].Error, ErrNotImage) {
+		t.Fatalf("Expected notes.txt to be skipped because it is not an image, %v", skipped[0].Error)
+	}
+
+	pb, _ = newTestPictureBook(t, func(opts *PictureBookOptions) {
+		opts.ErrorPolicy = ERROR_POLICY_STRICT
+	})
+
+	err = pb.AddPictures(ctx, []string{root})
+
+	if err == nil {
+		t.Fatalf("Expected strict error policy to fail")
+	}
+
+	opts, err := NewPictureBookDefaultOptions(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to create default options, %v", err)
+	}
+
+	opts.ErrorPolicy = "ignore"
+
+	_, err = NewPictureBook(ctx, opts)
+
+	if err == nil {
+		t.Fatalf("Expected invalid error policy to fail")
+	}
+}
+
+func TestErrorPolicyRender(t *testing.T) {
+
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+
+	err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+
+	if err != nil {
+		t.Fatalf("Failed to encode image, %v", err)
+	}
+
+	// Corrupt the compressed image data, after its zlib header, so that the image can be decoded by
+	// image.DecodeConfig, and read by fpdf, but fpdf fails to decompress it
+
+	corrupt := buf.Bytes()
+	idx := bytes.Index(corrupt, []byte("IDAT"))
+
+	corrupt[idx+6] = 0xff
+
+	root := t.TempDir()
+
+	writeTestFiles(t, root, map[string]string{
+		"a.png": "",
+		"b.png": string(corrupt),
+		"c.png": "",
+	})
+
+	pb, _ := newTestPictureBook(t, nil)
+
+	err = pb.AddPictures(ctx, []string{root})
+
+	if err != nil {
+		t.Fatalf("Failed to add pictures, %v", err)
+	}
+
+	skipped := pb.SkippedPictures()
+
+	if len(skipped) != 1 || filepath.Base(skipped[0].Path) != "b.png" {
+		t.Fatalf("Expected b.png to be skipped, %v", skipped)
+	}
+
+	pages := pb.Pages()
+
+	if len(pages) != 2 || filepath.Base(pages[1].Source) != "c.png" || pages[1].Page != 2 {
+		t.Fatalf("Unexpected pages: %v", pages)
+	}
+
+	err = pb.Save(ctx, "test.pdf")
+
+	if err != nil {
+		t.Fatalf("Failed to save picturebook, %v", err)
+	}
+
+	if pb.Summary().Pages[picture.PAGE_TYPE_PICTURE] != 2 {
+		t.Fatalf("Unexpected summary pages: %v", pb.Summary().Pages)
+	}
+}
+
+func TestErrorPolicyCancelled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	root := t.TempDir()
+
+	writeTestFiles(t, root, map[string]string{
+		"a.png": "",
+		"b.png": "",
+	})
+
+	pb, _ := newTestPictureBook(t, nil)
+
+	err := pb.AddPictures(ctx, []string{root})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected cancelled context to fail, %v", err)
+	}
+
+	if len(pb.Pages()) != 0 || len(pb.SkippedPictures()) != 0 {
+		t.Fatalf("Expected no pages or skipped pictures, %d, %d", len(pb.Pages()), len(pb.SkippedPictures()))
+	}
+}
+
+func TestEnsureSupportedFormat(t *testing.T) {
+
+	tests := map[string]bool{
+		"png":                    true,
+		"image/jpeg":             true,
+		"image/vnd.mozilla.apng": true,
+		"image/heic":             true,
+		"image/heif":             false,
+		"image/bmp":              false,
+	}
+
+	for format, expected := range tests {
+
+		err := ensureSupportedFormat(format)
+
+		if (err == nil) != expected {
+			t.Fatalf("Unexpected result for %s, %v", format, err)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 )
 
 func init() {
@@ -16,6 +17,19 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.FILTER, &handler.Description{
+		Scheme:  "any",
+		Summary: "Include all images.",
+		Syntax:  "any://",
+		Examples: []string{
+			"any://",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // type AnyFilter implements the `Filter` interface and allows any image to be included in a picturebook.
@@ -6,18 +6,38 @@ import (
 	"github.com/aaronland/go-picturebook/bucket"
 )
 
+// MultiFilterOptions defines configuration options for creating a new `MultiFilter` instance.
+type MultiFilterOptions struct {
+	// The `Filter` instances to test, in order.
+	Filters []Filter
+	// The URIs used to create each of the `Filter` instances in `Filters`. These are used to report which filter excluded an image.
+	URIs []string
+}
+
 // type MultiFilter implements the `Filter` interface and allows multiple `Filter` instances to be tested
 // to defermine whether an image should  be included in a picturebook.
 type MultiFilter struct {
 	Filter
 	filters []Filter
+	uris    []string
 }
 
 // NewMultiFilter returns a new instance of `MultiFilter` for 'filters'
 func NewMultiFilter(ctx context.Context, filters ...Filter) (Filter, error) {
 
+	opts := &MultiFilterOptions{
+		Filters: filters,
+	}
+
+	return NewMultiFilterWithOptions(ctx, opts)
+}
+
+// NewMultiFilterWithOptions returns a new instance of `MultiFilter` configured by 'opts'.
+func NewMultiFilterWithOptions(ctx context.Context, opts *MultiFilterOptions) (Filter, error) {
+
 	f := &MultiFilter{
-		filters: filters,
+		filters: opts.Filters,
+		uris:    opts.URIs,
 	}
 
 	return f, nil
@@ -28,18 +48,37 @@ func NewMultiFilter(ctx context.Context, filters ...Filter) (Filter, error) {
 // method to return true.
 func (f *MultiFilter) Continue(ctx context.Context, source_bucket bucket.Bucket, path string) (bool, error) {
 
-	for _, current_f := range f.filters {
+	ok, _, err := f.ContinueWithURI(ctx, source_bucket, path)
+	return ok, err
+}
+
+// ContinueWithURI returns a boolean value signaling whether or not 'path' should be included in a picturebook,
+// as with the `Continue` method, and the URI of the filter which excluded it. The URI is empty if 'path' is
+// included or if 'f' was not created with a list of URIs.
+func (f *MultiFilter) ContinueWithURI(ctx context.Context, source_bucket bucket.Bucket, path string) (bool, string, error) {
+
+	for idx, current_f := range f.filters {
 
 		ok, err := current_f.Continue(ctx, source_bucket, path)
 
 		if err != nil {
-			return false, err
+			return false, "", err
 		}
 
 		if !ok {
-			return false, nil
+			return false, f.uri(idx), nil
 		}
 	}
 
-	return true, nil
+	return true, "", nil
+}
+
+// uri returns the URI for the filter at position 'idx', if known.
+func (f *MultiFilter) uri(idx int) string {
+
+	if idx < len(f.uris) {
+		return f.uris[idx]
+	}
+
+	return ""
 }
@@ -7,6 +7,7 @@ import (
 	"regexp"
 
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 )
 
 func init() {
@@ -17,6 +18,37 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.FILTER, &handler.Description{
+		Scheme:  "regexp",
+		Summary: "Include, or exclude, images whose path matches a regular expression.",
+		Syntax:  "regexp://{MODE}?pattern={PATTERN}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "mode",
+				Location:    handler.PARAMETER_HOST,
+				Type:        "string",
+				Description: "Whether images whose path matches the pattern are included or excluded.",
+				Required:    true,
+				Values:      []string{"include", "exclude"},
+			},
+			{
+				Name:        "pattern",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "regexp",
+				Description: "A valid Go language regular expression used to test the path of each image.",
+				Required:    true,
+			},
+		},
+		Examples: []string{
+			"regexp://include?pattern=\\.jpg$",
+			"regexp://exclude?pattern=^draft-",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // type AnyFilter implements the `Filter` interface that determines whether an image should be included in a picturebook using a regular expression.
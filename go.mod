@@ -4,6 +4,7 @@ go 1.25.0
 
 require (
 	codeberg.org/go-pdf/fpdf v0.11.1
+	github.com/BurntSushi/toml v1.4.0
 	github.com/aaronland/go-image-contour/v2 v2.1.0
 	github.com/aaronland/go-image-halftone/v2 v2.0.0
 	github.com/aaronland/go-image/v2 v2.1.4
@@ -16,6 +17,8 @@ require (
 	github.com/sfomuseum/go-flags v0.12.1
 	github.com/sfomuseum/go-font-ocra v0.0.3
 	gocloud.dev v0.45.0
+	golang.org/x/image v0.38.0
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
@@ -55,7 +58,6 @@ require (
 	go.opentelemetry.io/otel/sdk/metric v1.43.0 // indirect
 	go.opentelemetry.io/otel/trace v1.43.0 // indirect
 	go4.org v0.0.0-20200411211856-f5505b9728dd // indirect
-	golang.org/x/image v0.38.0 // indirect
 	golang.org/x/net v0.48.0 // indirect
 	golang.org/x/sys v0.42.0 // indirect
 	golang.org/x/term v0.38.0 // indirect
@@ -65,5 +67,4 @@ require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20251202230838-ff82c1b0f217 // indirect
 	google.golang.org/grpc v1.79.3 // indirect
 	google.golang.org/protobuf v1.36.10 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
@@ -0,0 +1,149 @@
+// package handler provides methods for describing the URI-based handlers (captions, filters, outputs, processes,
+// progress monitors, sorters and texts) used to create picturebooks.
+package handler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CAPTION is the kind of handler registered using the `caption.RegisterCaption` method.
+const CAPTION string = "caption"
+
+// FILTER is the kind of handler registered using the `filter.RegisterFilter` method.
+const FILTER string = "filter"
+
+// OUTPUT is the kind of handler registered using the `output.RegisterOutput` method.
+const OUTPUT string = "output"
+
+// PROCESS is the kind of handler registered using the `process.RegisterProcess` method.
+const PROCESS string = "process"
+
+// PROGRESS is the kind of handler registered using the `progress.RegisterMonitor` method.
+const PROGRESS string = "progress"
+
+// SORT is the kind of handler registered using the `sort.RegisterSorter` method.
+const SORT string = "sort"
+
+// TEXT is the kind of handler registered using the `text.RegisterText` method.
+const TEXT string = "text"
+
+// PARAMETER_HOST signals that a parameter is read from the host component of a handler URI.
+const PARAMETER_HOST string = "host"
+
+// PARAMETER_PATH signals that a parameter is read from the path component of a handler URI.
+const PARAMETER_PATH string = "path"
+
+// PARAMETER_QUERY signals that a parameter is read from the query component of a handler URI.
+const PARAMETER_QUERY string = "query"
+
+// type Parameter defines a struct describing a single parameter accepted by a handler URI.
+type Parameter struct {
+	// The name of the parameter. For query parameters this is the name of the query parameter.
+	Name string `json:"name"`
+	// The component of the URI the parameter is read from. Valid options are "host", "path" and "query".
+	Location string `json:"location"`
+	// The type of the parameter's value, for example "string", "integer", "float" or "boolean".
+	Type string `json:"type"`
+	// A short description of the parameter.
+	Description string `json:"description"`
+	// The value used when the parameter is not present.
+	Default string `json:"default,omitempty"`
+	// A boolean flag signaling whether the parameter is required.
+	Required bool `json:"required"`
+	// A list of valid values for the parameter, if it only accepts a fixed set of values.
+	Values []string `json:"values,omitempty"`
+}
+
+// type Description defines a struct describing a handler and the syntax of its URIs.
+type Description struct {
+	// The URI scheme the handler is registered with.
+	Scheme string `json:"scheme"`
+	// A short description of what the handler does.
+	Summary string `json:"summary"`
+	// The syntax of the handler's URIs, for example "contour://?iterations={INTEGER}&scale={FLOAT}".
+	Syntax string `json:"syntax"`
+	// The parameters, if any, accepted by the handler's URIs.
+	Parameters []*Parameter `json:"parameters,omitempty"`
+	// One or more example URIs.
+	Examples []string `json:"examples,omitempty"`
+}
+
+// descriptions is a map of handler kinds to a map of URI schemes to their descriptions.
+var descriptions = make(map[string]map[string]*Description)
+
+var descriptions_mu = new(sync.RWMutex)
+
+// RegisterDescription associates 'desc' with the handler of kind 'kind' registered with the URI scheme `desc.Scheme`.
+// Handlers are expected to register their description alongside their initialization function, typically in an
+// `init` function. It is an error to register more than one description for the same kind and scheme.
+func RegisterDescription(ctx context.Context, kind string, desc *Description) error {
+
+	if kind == "" {
+		return fmt.Errorf("Missing handler kind")
+	}
+
+	if desc == nil || desc.Scheme == "" {
+		return fmt.Errorf("Missing handler scheme")
+	}
+
+	scheme := strings.ToLower(desc.Scheme)
+
+	descriptions_mu.Lock()
+	defer descriptions_mu.Unlock()
+
+	kind_descriptions, exists := descriptions[kind]
+
+	if !exists {
+		kind_descriptions = make(map[string]*Description)
+		descriptions[kind] = kind_descriptions
+	}
+
+	_, exists = kind_descriptions[scheme]
+
+	if exists {
+		return fmt.Errorf("A description for the %s:// %s handler has already been registered", scheme, kind)
+	}
+
+	kind_descriptions[scheme] = desc
+	return nil
+}
+
+// GetDescription returns the description registered for the handler of kind 'kind' with the URI scheme 'scheme'.
+func GetDescription(kind string, scheme string) (*Description, bool) {
+
+	descriptions_mu.RLock()
+	defer descriptions_mu.RUnlock()
+
+	kind_descriptions, exists := descriptions[kind]
+
+	if !exists {
+		return nil, false
+	}
+
+	desc, exists := kind_descriptions[strings.ToLower(scheme)]
+	return desc, exists
+}
+
+// Descriptions returns the list of descriptions registered for handlers of kind 'kind', sorted by URI scheme.
+func Descriptions(kind string) []*Description {
+
+	descriptions_mu.RLock()
+	defer descriptions_mu.RUnlock()
+
+	kind_descriptions := descriptions[kind]
+	list := make([]*Description, 0, len(kind_descriptions))
+
+	for _, desc := range kind_descriptions {
+		list = append(list, desc)
+	}
+
+	slices.SortFunc(list, func(a *Description, b *Description) int {
+		return strings.Compare(a.Scheme, b.Scheme)
+	})
+
+	return list
+}
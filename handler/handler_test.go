@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRegisterDescription(t *testing.T) {
+
+	ctx := context.Background()
+
+	kind := "test"
+
+	for _, scheme := range []string{"b", "a"} {
+
+		err := RegisterDescription(ctx, kind, &Description{
+			Scheme:  scheme,
+			Summary: "A test handler.",
+			Syntax:  scheme + "://",
+		})
+
+		if err != nil {
+			t.Fatalf("Failed to register description for %s, %v", scheme, err)
+		}
+	}
+
+	err := RegisterDescription(ctx, kind, &Description{Scheme: "A"})
+
+	if err == nil {
+		t.Fatalf("Expected duplicate description to be rejected")
+	}
+
+	err = RegisterDescription(ctx, kind, &Description{})
+
+	if err == nil {
+		t.Fatalf("Expected description without a scheme to be rejected")
+	}
+
+	_, exists := GetDescription(kind, "A")
+
+	if !exists {
+		t.Fatalf("Expected description for 'a' to exist")
+	}
+
+	list := Descriptions(kind)
+
+	if len(list) != 2 || list[0].Scheme != "a" || list[1].Scheme != "b" {
+		t.Fatalf("Unexpected descriptions %v", list)
+	}
+}
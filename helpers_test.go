@@ -0,0 +1,121 @@
+package picturebook
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/progress"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+// newTestPictureBook returns a new `PictureBook` instance, created with default options modified by 'fn' if it is not nil, which
+// reads images from the local filesystem and writes the picturebook, and any temporary files, to temporary directories. It also
+// returns the path of the directory the picturebook is written to.
+func newTestPictureBook(t *testing.T, fn func(*PictureBookOptions)) (*PictureBook, string) {
+
+	t.Helper()
+
+	ctx := context.Background()
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to register buckets, %v", err)
+	}
+
+	target_root := t.TempDir()
+
+	source, err := bucket.NewBucket(ctx, "file:///")
+
+	if err != nil {
+		t.Fatalf("Failed to create source bucket, %v", err)
+	}
+
+	target, err := bucket.NewBucket(ctx, fmt.Sprintf("file://%s?metadata=skip", target_root))
+
+	if err != nil {
+		t.Fatalf("Failed to create target bucket, %v", err)
+	}
+
+	tmp, err := bucket.NewBucket(ctx, fmt.Sprintf("file://%s?metadata=skip", t.TempDir()))
+
+	if err != nil {
+		t.Fatalf("Failed to create temporary bucket, %v", err)
+	}
+
+	monitor, err := progress.NewMonitor(ctx, "null://")
+
+	if err != nil {
+		t.Fatalf("Failed to create monitor, %v", err)
+	}
+
+	opts, err := NewPictureBookDefaultOptions(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to create default options, %v", err)
+	}
+
+	opts.Source = source
+	opts.Target = target
+	opts.Temporary = tmp
+	opts.Monitor = monitor
+
+	if fn != nil {
+		fn(opts)
+	}
+
+	pb, err := NewPictureBook(ctx, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create picturebook, %v", err)
+	}
+
+	return pb, target_root
+}
+
+// writeTestFiles writes each of the files in 'files', a map of filenames to file contents, to 'root'. Filenames with a ".png"
+// extension and an empty body are written as 40 x 20 pixel PNG images.
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+
+	t.Helper()
+
+	for fname, body := range files {
+
+		path := filepath.Join(root, fname)
+
+		if body != "" || filepath.Ext(fname) != ".png" {
+
+			err := os.WriteFile(path, []byte(body), 0644)
+
+			if err != nil {
+				t.Fatalf("Failed to write %s, %v", fname, err)
+			}
+
+			continue
+		}
+
+		fh, err := os.Create(path)
+
+		if err != nil {
+			t.Fatalf("Failed to create %s, %v", fname, err)
+		}
+
+		err = png.Encode(fh, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s, %v", fname, err)
+		}
+
+		err = fh.Close()
+
+		if err != nil {
+			t.Fatalf("Failed to close %s, %v", fname, err)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package picturebook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aaronland/go-picturebook/filter"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// type InspectOptions defines a struct containing configuration information for inspecting the images in a picturebook's sources.
+type InspectOptions struct {
+	// A list of URIs used to create the `filter.Filter` instances tested against each image. Each filter is tested individually,
+	// in order, so that the filter which rejected an image can be reported. If empty the picturebook's `Filter` option is tested instead.
+	FilterURIs []string
+}
+
+// type FilterInspection defines a struct containing the outcome of testing a single filter against an image.
+type FilterInspection struct {
+	// The URI of the filter. If the picturebook's own `Filter` option is tested, and it is a `filter.MultiFilter` instance, this
+	// is the URI of the filter which rejected the image, if known. Otherwise it is empty if the filter was not created from a URI.
+	URI string `json:"uri"`
+	// A boolean flag signaling whether the filter included the image.
+	Passed bool `json:"passed"`
+	// The error, if any, reported by the filter.
+	Error string `json:"error,omitempty"`
+}
+
+// type PictureInspection defines a struct containing diagnostic information about a file in a picturebook's sources.
+type PictureInspection struct {
+	// The path of the file, as gathered from the picturebook's sources.
+	Path string `json:"path"`
+	// The mimetype detected for the file.
+	MimeType string `json:"mimetype"`
+	// A boolean flag signaling whether the file would be included in the picturebook.
+	Included bool `json:"included"`
+	// The outcome of testing each filter against the file.
+	Filters []*FilterInspection `json:"filters,omitempty"`
+	// The URI of the first filter to reject the file, if any.
+	RejectedBy string `json:"rejected_by,omitempty"`
+	// The caption derived for the file.
+	Caption string `json:"caption,omitempty"`
+	// The text derived for the file.
+	Text string `json:"text,omitempty"`
+	// The width of the image in pixels.
+	Width int `json:"width,omitempty"`
+	// The height of the image in pixels.
+	Height int `json:"height,omitempty"`
+	// The EXIF DateTime property of the image, if present.
+	DateTime *time.Time `json:"datetime,omitempty"`
+	// The effective resolution, in dots per inch, of the image when placed on a page of the picturebook.
+	DPI float64 `json:"dpi,omitempty"`
+	// The reason, if any, that the file would be excluded from the picturebook or could not be fully inspected.
+	Error string `json:"error,omitempty"`
+}
+
+// Inspect collects all the images in one or more folders defined by 'paths' and returns a `PictureInspection` for each file
+// describing how it would be treated by the picturebook: its mimetype, the outcome of each filter, its caption and text,
+// its dimensions, its EXIF DateTime property and its effective resolution at the picturebook's page size. Images are not
+// processed and nothing is added to the picturebook.
+func (pb *PictureBook) Inspect(ctx context.Context, paths []string, opts *InspectOptions) ([]*PictureInspection, error) {
+
+	type namedFilter struct {
+		uri string
+		f   filter.Filter
+	}
+
+	filters := make([]*namedFilter, 0)
+
+	if len(opts.FilterURIs) > 0 {
+
+		for _, filter_uri := range opts.FilterURIs {
+
+			f, err := filter.NewFilter(ctx, filter_uri)
+
+			if err != nil {
+				return nil, fmt.Errorf("Failed to create filter '%s', %w", filter_uri, err)
+			}
+
+			filters = append(filters, &namedFilter{uri: filter_uri, f: f})
+		}
+
+	} else if pb.Options.Filter != nil {
+		filters = append(filters, &namedFilter{f: pb.Options.Filter})
+	}
+
+	inspections := make([]*PictureInspection, 0)
+
+	for path, err := range pb.Options.Source.GatherPictures(ctx, paths...) {
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to gather pictures, %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			// pass
+		}
+
+		i := &PictureInspection{
+			Path:     path,
+			Included: true,
+		}
+
+		inspections = append(inspections, i)
+
+		err := pb.inspectPicture(ctx, i)
+
+		if err != nil {
+			i.Included = false
+			i.Error = err.Error()
+			continue
+		}
+
+		for _, nf := range filters {
+
+			fi := &FilterInspection{
+				URI: nf.uri,
+			}
+
+			i.Filters = append(i.Filters, fi)
+
+			var ok bool
+			var err error
+
+			// The picturebook's own filter is not created from a single URI but, if it is a MultiFilter, it
+			// can report the URI of the filter which rejected the image
+
+			if mf, is_multi := nf.f.(*filter.MultiFilter); is_multi && nf.uri == "" {
+				ok, fi.URI, err = mf.ContinueWithURI(ctx, pb.Options.Source, path)
+			} else {
+				ok, err = nf.f.Continue(ctx, pb.Options.Source, path)
+			}
+
+			if err != nil {
+				fi.Error = err.Error()
+			}
+
+			fi.Passed = ok && err == nil
+
+			if !fi.Passed && i.Included {
+				i.Included = false
+				i.RejectedBy = fi.URI
+			}
+		}
+
+		if pb.Options.Caption != nil {
+
+			txt, err := pb.Options.Caption.Text(ctx, pb.Options.Source, path)
+
+			if err != nil {
+				i.Included = false
+				i.Error = fmt.Sprintf("Failed to derive caption text, %v", err)
+				continue
+			}
+
+			i.Caption = txt
+		}
+
+		if pb.Options.Text != nil {
+
+			txt, err := pb.Options.Text.Body(ctx, pb.Options.Source, path)
+
+			if err != nil {
+				i.Included = false
+				i.Error = fmt.Sprintf("Failed to derive text body, %v", err)
+				continue
+			}
+
+			i.Text = txt
+		}
+
+		if i.Width > 0 && i.Height > 0 {
+
+			w := float64(i.Width)
+			h := float64(i.Height)
+
+			if pb.Options.FillPage && pb.Layout.RotateToFill(w, h) {
+				w, h = h, w
+			}
+
+			placement, err := pb.Layout.Picture(w, h, i.Caption)
+
+			if err == nil {
+				i.DPI = effectiveDPI(int(w), placement.Image.Width)
+			}
+		}
+	}
+
+	return inspections, nil
+}
+
+// inspectPicture assigns the mimetype, dimensions and EXIF DateTime property of the file at 'i.Path' to 'i'. An error
+// is returned if the file can not be read or is not an image, which is to say it would be excluded from the picturebook.
+func (pb *PictureBook) inspectPicture(ctx context.Context, i *PictureInspection) error {
+
+	logger := slog.Default()
+	logger = logger.With("path", i.Path)
+
+	parts := strings.Split(i.Path, "#")
+
+	r, err := pb.Options.Source.NewReader(ctx, parts[0], nil)
+
+	if err != nil {
+		return fmt.Errorf("Failed to open file, %w", err)
+	}
+
+	defer r.Close()
+
+	mtype, err := mimetype.DetectReader(r)
+
+	if err != nil {
+		return fmt.Errorf("Failed to detect mimetype, %w", err)
+	}
+
+	i.MimeType = mtype.String()
+
+	if !strings.HasPrefix(i.MimeType, "image/") {
+		return ErrNotImage
+	}
+
+	_, err = r.Seek(0, io.SeekStart)
+
+	if err != nil {
+		return fmt.Errorf("Failed to rewind reader, %w", err)
+	}
+
+	var format string
+
+	im_cfg, err := decodeImageConfig(ctx, r)
+
+	if err == nil {
+		i.Width = im_cfg.Width
+		i.Height = im_cfg.Height
+		format = im_cfg.Format
+	} else {
+
+		// For example, HEIC images which are not supported by image.DecodeConfig
+
+		im, im_format, err := decodeImage(ctx, r)
+
+		if err != nil {
+			return fmt.Errorf("Failed to decode image, %w", err)
+		}
+
+		dims := im.Bounds()
+
+		i.Width = dims.Dx()
+		i.Height = dims.Dy()
+		format = im_format
+	}
+
+	err = ensureSupportedFormat(format)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Seek(0, io.SeekStart)
+
+	if err != nil {
+		return fmt.Errorf("Failed to rewind reader, %w", err)
+	}
+
+	x, err := exif.Decode(r)
+
+	if err != nil {
+		logger.Debug("Failed to decode EXIF data", "error", err)
+		return nil
+	}
+
+	dt, err := x.DateTime()
+
+	if err != nil {
+		logger.Debug("Failed to derive EXIF DateTime", "error", err)
+		return nil
+	}
+
+	i.DateTime = &dt
+	return nil
+}
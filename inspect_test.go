@@ -0,0 +1,70 @@
+package picturebook
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/aaronland/go-picturebook/filter"
+)
+
+func TestInspectPictureBookFilter(t *testing.T) {
+
+	ctx := context.Background()
+
+	root := t.TempDir()
+
+	writeTestFiles(t, root, map[string]string{
+		"keep.png": "",
+		"skip.png": "",
+	})
+
+	filter_uri := "regexp://exclude?pattern=skip"
+
+	f, err := filter.NewFilter(ctx, filter_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create filter, %v", err)
+	}
+
+	multi_filter, err := filter.NewMultiFilterWithOptions(ctx, &filter.MultiFilterOptions{
+		Filters: []filter.Filter{f},
+		URIs:    []string{filter_uri},
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to create multi filter, %v", err)
+	}
+
+	pb, _ := newTestPictureBook(t, func(opts *PictureBookOptions) {
+		opts.Filter = multi_filter
+	})
+
+	// Without any filter URIs the picturebook's own filter is tested
+
+	inspections, err := pb.Inspect(ctx, []string{root}, &InspectOptions{})
+
+	if err != nil {
+		t.Fatalf("Failed to inspect pictures, %v", err)
+	}
+
+	for _, i := range inspections {
+
+		switch filepath.Base(i.Path) {
+		case "keep.png":
+
+			if !i.Included || i.RejectedBy != "" {
+				t.Fatalf("Unexpected inspection for keep.png: %+v", i)
+			}
+
+		case "skip.png":
+
+			if i.Included || i.RejectedBy != filter_uri {
+				t.Fatalf("Unexpected inspection for skip.png: %+v", i)
+			}
+
+		default:
+			t.Fatalf("Unexpected path %s", i.Path)
+		}
+	}
+}
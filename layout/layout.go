@@ -0,0 +1,277 @@
+// Package layout provides methods for calculating the position and size of the images and text placed on
+// the pages of a picturebook, independent of how those pages are eventually rendered.
+package layout
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aaronland/go-picturebook/picture"
+	"github.com/aaronland/go-picturebook/text"
+)
+
+// POINTS_PER_INCH is the number of (typographic) points in an inch.
+const POINTS_PER_INCH float64 = 72.0
+
+// type TextMeasurer defines an interface for measuring the width of strings in the font used to draw text on a page.
+type TextMeasurer interface {
+	// StringWidth returns the width, in inches, of 'txt' drawn at font size 'size' (measured in points).
+	StringWidth(txt string, size float64) float64
+}
+
+// type Insets defines a struct containing the size of the space around the four sides of a box.
+type Insets struct {
+	Top    float64
+	Bottom float64
+	Left   float64
+	Right  float64
+}
+
+// type Layout defines a struct containing the settings used to calculate the position of images and text on a page.
+// For consistency with the rest of the picturebook package, unless otherwise noted, lengths are measured in "dots"
+// (inches multiplied by `DPI`).
+type Layout struct {
+	// The number of dots per inch used to express lengths.
+	DPI float64
+	// The orientation of the page: "P" (portrait) or "L" (landscape).
+	Orientation string
+	// The width of the page, in inches.
+	PageWidth float64
+	// The height of the page, in inches.
+	PageHeight float64
+	// The margins around each page, inclusive of page bleed.
+	Margins Insets
+	// The border around each image.
+	Borders Insets
+	// The width of the area available for images and text.
+	CanvasWidth float64
+	// The height of the area available for images and text.
+	CanvasHeight float64
+	// The size, in points, of the font used to draw text.
+	FontSize float64
+	// The margin between lines of text. For legacy reasons this value is added to both inches and dots.
+	TextMargin float64
+	// The space, in inches, a renderer adds before the start of a line of text.
+	CellMargin float64
+	// The TextMeasurer used to determine the width of lines of text.
+	Measurer TextMeasurer
+}
+
+// type Placement defines a struct containing the position and size of an image, its border and its caption on a page.
+type Placement struct {
+	// The position and size of the image, in inches.
+	Image *picture.PictureBookBox
+	// The position and size of the border drawn behind the image, in inches. This is nil if no border is drawn.
+	Border *picture.PictureBookBox
+	// The lines of the image's caption.
+	Lines []*picture.PictureBookTextRun
+}
+
+// LineHeight returns the height, in inches, of a line of text drawn at font size 'size' (measured in points).
+func LineHeight(size float64) float64 {
+	return size / POINTS_PER_INCH
+}
+
+// RotateToFill returns a boolean value indicating whether an image measuring 'w' x 'h' pixels should be rotated 90 degrees
+// in order to better fill the page.
+func (l *Layout) RotateToFill(w float64, h float64) bool {
+
+	line_h := LineHeight(l.FontSize)
+
+	max_w := l.CanvasWidth
+	max_h := l.CanvasHeight - (l.TextMargin + line_h)
+
+	switch {
+	case l.Orientation == "P" && w > h && w > max_w:
+		return true
+	case l.Orientation == "L" && h > w && h > max_h:
+		return true
+	default:
+		return false
+	}
+}
+
+// Picture returns the placement of an image measuring 'w' x 'h' pixels, and its (optional) caption, on a page. The image is
+// scaled to fit the canvas, leaving room for its caption, and centered. Caption lines are right-aligned beneath the image.
+func (l *Layout) Picture(w float64, h float64, caption string) (*Placement, error) {
+
+	if w == 0.0 || h == 0.0 {
+		return nil, fmt.Errorf("Image has zero-sized dimension")
+	}
+
+	x := l.Margins.Left
+	y := l.Margins.Top
+
+	max_w := l.CanvasWidth
+	max_h := l.CanvasHeight
+
+	// Adjust height relative to caption so that it (the caption) doesn't spill in to the margin.
+	// Note that the caption height mixes the font size (in points) with dots; this is how it's
+	// always been and changing it would move every image in every existing picturebook.
+
+	caption_h := 0.0
+
+	if caption != "" {
+
+		count := len(strings.Split(caption, "\n"))
+
+		caption_h = (l.FontSize + 2 + l.TextMargin) * float64(count)
+		max_h = max_h - caption_h
+	}
+
+	for {
+
+		if w >= max_w || h >= max_h {
+
+			if w > max_w {
+
+				ratio := max_w / w
+				w = max_w
+				h = h * ratio
+			}
+
+			if (h + caption_h) > max_h {
+
+				ratio := max_h / (h + caption_h)
+				w = w * ratio
+				h = max_h
+			}
+		}
+
+		if w <= max_w && h <= max_h {
+			break
+		}
+	}
+
+	if w < max_w {
+		padding := max_w - w
+		x = x + (padding / 2.0)
+	}
+
+	if h < max_h {
+		padding := max_h - h
+		y = y + (padding / 2.0)
+	}
+
+	p := &Placement{
+		Image: &picture.PictureBookBox{
+			X:      x / l.DPI,
+			Y:      y / l.DPI,
+			Width:  w / l.DPI,
+			Height: h / l.DPI,
+		},
+		Lines: make([]*picture.PictureBookTextRun, 0),
+	}
+
+	if l.Borders.Right > 0.0 {
+
+		p.Border = &picture.PictureBookBox{
+			X:      (x - l.Borders.Left) / l.DPI,
+			Y:      (y - l.Borders.Top) / l.DPI,
+			Width:  (w + l.Borders.Left + l.Borders.Right) / l.DPI,
+			Height: (h + l.Borders.Top + l.Borders.Bottom) / l.DPI,
+		}
+	}
+
+	if caption == "" {
+		return p, nil
+	}
+
+	txt_h := LineHeight(l.FontSize) + l.TextMargin
+	line_h := LineHeight(l.FontSize + 2)
+
+	current_y := y
+
+	for txt := range strings.SplitSeq(caption, "\n") {
+
+		txt = strings.TrimSpace(txt)
+
+		txt_w := l.Measurer.StringWidth(txt, l.FontSize) + l.TextMargin
+
+		p.Lines = append(p.Lines, &picture.PictureBookTextRun{
+			Text:   txt,
+			X:      ((x + w) / l.DPI) - txt_w,
+			Y:      ((current_y + h) / l.DPI) + line_h,
+			Height: line_h,
+			Size:   l.FontSize,
+		})
+
+		current_y += ((txt_h * l.DPI) * .65)
+	}
+
+	return p, nil
+}
+
+// Text returns the lines of 'body', wrapped to the width of the canvas, starting at the top-left corner of the canvas.
+func (l *Layout) Text(body string) []*picture.PictureBookTextRun {
+
+	txt_h := LineHeight(l.FontSize) + l.TextMargin
+	line_h := LineHeight(l.FontSize + 2)
+
+	current_x := l.Margins.Left
+	current_y := l.Margins.Top
+
+	m := &sizedMeasurer{
+		measurer: l.Measurer,
+		size:     l.FontSize,
+	}
+
+	prepped := text.PrepareText(m, l.DPI, l.CanvasWidth, body)
+	lines := make([]*picture.PictureBookTextRun, len(prepped))
+
+	for i, txt := range prepped {
+
+		lines[i] = &picture.PictureBookTextRun{
+			Text:   strings.TrimSpace(txt),
+			X:      current_x / l.DPI,
+			Y:      current_y / l.DPI,
+			Height: line_h,
+			Size:   l.FontSize,
+		}
+
+		current_y += ((txt_h * l.DPI) * .65)
+	}
+
+	return lines
+}
+
+// Section returns the lines of 'title', drawn at twice the font size and centered on the page.
+func (l *Layout) Section(title string) []*picture.PictureBookTextRun {
+
+	font_sz := l.FontSize * 2
+	line_h := LineHeight(font_sz) + l.TextMargin
+
+	title_lines := strings.Split(title, "\n")
+	lines := make([]*picture.PictureBookTextRun, len(title_lines))
+
+	y := (l.PageHeight - (line_h * float64(len(title_lines)))) / 2.0
+
+	for i, txt := range title_lines {
+
+		txt = strings.TrimSpace(txt)
+
+		// Lines are drawn starting at X plus the renderer's cell margin
+		x := ((l.PageWidth - l.Measurer.StringWidth(txt, font_sz)) / 2.0) - l.CellMargin
+
+		lines[i] = &picture.PictureBookTextRun{
+			Text:   txt,
+			X:      x,
+			Y:      y + (line_h * float64(i)),
+			Height: line_h,
+			Size:   font_sz,
+		}
+	}
+
+	return lines
+}
+
+// sizedMeasurer implements the `text.StringMeasurer` interface for a `TextMeasurer` instance at a fixed font size.
+type sizedMeasurer struct {
+	measurer TextMeasurer
+	size     float64
+}
+
+// GetStringWidth returns the width, in inches, of 's'.
+func (m *sizedMeasurer) GetStringWidth(s string) float64 {
+	return m.measurer.StringWidth(s, m.size)
+}
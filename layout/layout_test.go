@@ -0,0 +1,192 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+// fixedMeasurer measures every character as being half as wide as the font size.
+type fixedMeasurer struct{}
+
+func (m *fixedMeasurer) StringWidth(txt string, size float64) float64 {
+	return float64(len([]rune(txt))) * LineHeight(size) * 0.5
+}
+
+// newTestLayout returns a portrait, letter-sized layout at 100 DPI with half-inch margins.
+func newTestLayout() *Layout {
+
+	return &Layout{
+		DPI:          100.0,
+		Orientation:  "P",
+		PageWidth:    8.0,
+		PageHeight:   11.0,
+		Margins:      Insets{Top: 50.0, Bottom: 50.0, Left: 50.0, Right: 50.0},
+		CanvasWidth:  700.0,
+		CanvasHeight: 1000.0,
+		FontSize:     8.0,
+		TextMargin:   0.1,
+		CellMargin:   0.04,
+		Measurer:     &fixedMeasurer{},
+	}
+}
+
+func equals(a float64, b float64) bool {
+	return math.Abs(a-b) < 0.0001
+}
+
+func TestPicture(t *testing.T) {
+
+	l := newTestLayout()
+
+	p, err := l.Picture(1400.0, 700.0, "")
+
+	if err != nil {
+		t.Fatalf("Failed to place picture, %v", err)
+	}
+
+	im := p.Image
+
+	if !equals(im.X, 0.5) || !equals(im.Y, 3.75) || !equals(im.Width, 7.0) || !equals(im.Height, 3.5) {
+		t.Fatalf("Unexpected placement %v", im)
+	}
+
+	if p.Border != nil {
+		t.Fatalf("Unexpected border")
+	}
+
+	if len(p.Lines) != 0 {
+		t.Fatalf("Unexpected caption lines")
+	}
+
+	_, err = l.Picture(0.0, 700.0, "")
+
+	if err == nil {
+		t.Fatalf("Expected zero-sized image to fail")
+	}
+}
+
+func TestPictureWithCaptionAndBorder(t *testing.T) {
+
+	l := newTestLayout()
+	l.Borders = Insets{Top: 10.0, Bottom: 10.0, Left: 10.0, Right: 10.0}
+
+	p, err := l.Picture(1400.0, 700.0, "Hello\nworld!")
+
+	if err != nil {
+		t.Fatalf("Failed to place picture, %v", err)
+	}
+
+	im := p.Image
+
+	// The image is moved up to leave room for the caption
+
+	if !equals(im.Y, 3.649) || !equals(im.Height, 3.5) {
+		t.Fatalf("Unexpected placement %v", im)
+	}
+
+	if p.Border == nil {
+		t.Fatalf("Missing border")
+	}
+
+	if !equals(p.Border.X, im.X-0.1) || !equals(p.Border.Width, im.Width+0.2) {
+		t.Fatalf("Unexpected border %v", p.Border)
+	}
+
+	if len(p.Lines) != 2 {
+		t.Fatalf("Unexpected number of caption lines: %d", len(p.Lines))
+	}
+
+	for _, ln := range p.Lines {
+
+		right := ln.X + l.Measurer.StringWidth(ln.Text, ln.Size) + l.TextMargin
+
+		if !equals(right, im.X+im.Width) {
+			t.Fatalf("Caption line '%s' is not right-aligned with image (%f)", ln.Text, right)
+		}
+
+		if ln.Y < im.Y+im.Height {
+			t.Fatalf("Caption line '%s' overlaps image", ln.Text)
+		}
+	}
+
+	if p.Lines[1].Y <= p.Lines[0].Y {
+		t.Fatalf("Caption lines are out of order")
+	}
+}
+
+func TestRotateToFill(t *testing.T) {
+
+	l := newTestLayout()
+
+	if !l.RotateToFill(1400.0, 700.0) {
+		t.Fatalf("Expected wide landscape image to be rotated on a portrait page")
+	}
+
+	if l.RotateToFill(600.0, 300.0) {
+		t.Fatalf("Did not expect narrow landscape image to be rotated on a portrait page")
+	}
+
+	if l.RotateToFill(700.0, 1400.0) {
+		t.Fatalf("Did not expect portrait image to be rotated on a portrait page")
+	}
+}
+
+func TestText(t *testing.T) {
+
+	l := newTestLayout()
+
+	// Each character is 0.0556 inches wide so the canvas fits 126 characters per line
+
+	body := "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat.\nThe end."
+
+	lines := l.Text(body)
+
+	if len(lines) != 3 {
+		t.Fatalf("Unexpected number of lines: %d", len(lines))
+	}
+
+	for i, ln := range lines {
+
+		if !equals(ln.X, 0.5) {
+			t.Fatalf("Unexpected X for line %d: %f", i, ln.X)
+		}
+
+		if i > 0 && ln.Y <= lines[i-1].Y {
+			t.Fatalf("Line %d is out of order", i)
+		}
+	}
+
+	if lines[2].Text != "The end." {
+		t.Fatalf("Unexpected last line '%s'", lines[2].Text)
+	}
+}
+
+func TestSection(t *testing.T) {
+
+	l := newTestLayout()
+
+	lines := l.Section("Part\nOne")
+
+	if len(lines) != 2 {
+		t.Fatalf("Unexpected number of lines: %d", len(lines))
+	}
+
+	for _, ln := range lines {
+
+		if ln.Size != 16.0 {
+			t.Fatalf("Unexpected font size %f", ln.Size)
+		}
+
+		center := ln.X + l.CellMargin + (l.Measurer.StringWidth(ln.Text, ln.Size) / 2.0)
+
+		if !equals(center, l.PageWidth/2.0) {
+			t.Fatalf("Line '%s' is not centered (%f)", ln.Text, center)
+		}
+	}
+
+	mid := (lines[0].Y + lines[1].Y + lines[1].Height) / 2.0
+
+	if !equals(mid, l.PageHeight/2.0) {
+		t.Fatalf("Lines are not centered vertically (%f)", mid)
+	}
+}
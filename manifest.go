@@ -0,0 +1,226 @@
+package picturebook
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/picture"
+)
+
+// The format for manifest files encoded as JSON.
+const MANIFEST_FORMAT_JSON string = "json"
+
+// The format for manifest files encoded as CSV.
+const MANIFEST_FORMAT_CSV string = "csv"
+
+// The character used to prefix the line containing the options used to create a picturebook in manifest files encoded as CSV.
+const MANIFEST_CSV_COMMENT rune = '#'
+
+// type PictureBookManifest defines a struct containing details about a picturebook and each of its pages.
+type PictureBookManifest struct {
+	// The `PictureBookOptions` used to create the picturebook.
+	Options *PictureBookOptions `json:"options"`
+	// The list of `picture.PictureBookPage` instances describing each of the pages in the picturebook.
+	Pages []*picture.PictureBookPage `json:"pages"`
+}
+
+// Pages returns the list of `picture.PictureBookPage` instances describing each of the pages that have been added to the picturebook.
+func (pb *PictureBook) Pages() []*picture.PictureBookPage {
+	return pb.manifest
+}
+
+// recordPage appends 'page' to the picturebook's internal manifest, assigning it the next page number.
+// This method is expected to be called while the picturebook's `Mutex` is locked.
+func (pb *PictureBook) recordPage(page *picture.PictureBookPage) {
+	page.Page = len(pb.manifest) + 1
+	pb.manifest = append(pb.manifest, page)
+}
+
+// Manifest returns a `PictureBookManifest` instance describing the picturebook.
+func (pb *PictureBook) Manifest() *PictureBookManifest {
+
+	m := &PictureBookManifest{
+		Options: pb.Options,
+		Pages:   pb.Pages(),
+	}
+
+	return m
+}
+
+// manifestPath returns the path for a manifest file, encoded as 'format', associated with the picturebook at 'path'.
+func manifestPath(path string, format string) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.%s", strings.TrimSuffix(path, ext), format)
+}
+
+// SaveManifest will write a manifest describing the picturebook to 'path' in the `Target` bucket specified in the `PictureBookOptions`
+// used to create the picturebook. If 'path' has a ".csv" extension the list of pages is encoded as CSV,
+// preceded by a comment line containing the options used to create the picturebook, otherwise the manifest is encoded as JSON.
+func (pb *PictureBook) SaveManifest(ctx context.Context, path string) error {
+
+	if pb.Options.Target == nil {
+		return fmt.Errorf("Missing or invalid target bucket")
+	}
+
+	wr, err := pb.Options.Target.NewWriter(ctx, path, nil)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create a new writer for %s, %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		err = writeManifestCSV(wr, pb.Manifest())
+	default:
+		err = writeManifestJSON(wr, pb.Manifest())
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed to encode manifest for %s, %w", path, err)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		return fmt.Errorf("Failed to close writer for %s, %w", path, err)
+	}
+
+	return nil
+}
+
+// writeManifestJSON writes 'm' encoded as JSON to 'wr'.
+func writeManifestJSON(wr io.Writer, m *PictureBookManifest) error {
+
+	enc := json.NewEncoder(wr)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(m)
+}
+
+// writeManifestCSV writes the pages in 'm' encoded as CSV to 'wr'. The options used to create the picturebook are encoded
+// as JSON and written, before the CSV header, on a single line prefixed by `MANIFEST_CSV_COMMENT` so that they can be skipped
+// by CSV readers which support comments (for example by setting `csv.Reader.Comment`).
+func writeManifestCSV(wr io.Writer, m *PictureBookManifest) error {
+
+	enc_opts, err := json.Marshal(m.Options)
+
+	if err != nil {
+		return fmt.Errorf("Failed to encode options, %w", err)
+	}
+
+	_, err = fmt.Fprintf(wr, "%c options %s\n", MANIFEST_CSV_COMMENT, enc_opts)
+
+	if err != nil {
+		return err
+	}
+
+	csv_wr := csv.NewWriter(wr)
+
+	header := []string{
+		"page",
+		"type",
+		"source",
+		"path",
+		"caption",
+		"text",
+		"pixel_width",
+		"pixel_height",
+		"x",
+		"y",
+		"width",
+		"height",
+		"effective_dpi",
+		"border_x",
+		"border_y",
+		"border_width",
+		"border_height",
+		"checksum",
+	}
+
+	err = csv_wr.Write(header)
+
+	if err != nil {
+		return err
+	}
+
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	for _, p := range m.Pages {
+
+		// Pages without a border, for example text pages, have empty border columns
+
+		border := make([]string, 4)
+
+		if p.Border != nil {
+			border = []string{
+				formatFloat(p.Border.X),
+				formatFloat(p.Border.Y),
+				formatFloat(p.Border.Width),
+				formatFloat(p.Border.Height),
+			}
+		}
+
+		row := []string{
+			strconv.Itoa(p.Page),
+			p.Type,
+			p.Source,
+			p.Path,
+			p.Caption,
+			p.Text,
+			strconv.Itoa(p.PixelWidth),
+			strconv.Itoa(p.PixelHeight),
+			formatFloat(p.X),
+			formatFloat(p.Y),
+			formatFloat(p.Width),
+			formatFloat(p.Height),
+			formatFloat(p.EffectiveDPI),
+			border[0],
+			border[1],
+			border[2],
+			border[3],
+			p.Checksum,
+		}
+
+		err := csv_wr.Write(row)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	csv_wr.Flush()
+	return csv_wr.Error()
+}
+
+// sha256Checksum returns the hex-encoded SHA-256 checksum of the record named 'path' in 'b'.
+func sha256Checksum(ctx context.Context, b bucket.Bucket, path string) (string, error) {
+
+	r, err := b.NewReader(ctx, path, nil)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to create new reader for %s, %w", path, err)
+	}
+
+	defer r.Close()
+
+	h := sha256.New()
+
+	_, err = io.Copy(h, r)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to read %s, %w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
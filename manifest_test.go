@@ -0,0 +1,55 @@
+package picturebook
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestWriteManifestCSV(t *testing.T) {
+
+	ctx := context.Background()
+
+	pb, _ := newTestPictureBook(t, nil)
+
+	err := pb.AddImage(ctx, image.NewRGBA(image.Rect(0, 0, 40, 20)), nil)
+
+	if err != nil {
+		t.Fatalf("Failed to add image, %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	err = writeManifestCSV(&buf, pb.Manifest())
+
+	if err != nil {
+		t.Fatalf("Failed to write manifest, %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "# options {") {
+		t.Fatalf("Expected manifest to start with build options")
+	}
+
+	r := csv.NewReader(&buf)
+	r.Comment = MANIFEST_CSV_COMMENT
+
+	rows, err := r.ReadAll()
+
+	if err != nil {
+		t.Fatalf("Failed to read manifest, %v", err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("Unexpected number of rows: %d", len(rows))
+	}
+
+	for i, col := range rows[0] {
+
+		if col == "border_width" && rows[1][i] == "" {
+			t.Fatalf("Missing border width for picture page")
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package picturebook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/picture"
+	"github.com/aaronland/go-picturebook/tempfile"
+	_ "gocloud.dev/blob/memblob"
+)
+
+// type PictureOptions defines a struct containing optional details for images added to a picturebook using the `AddImage` and `AddReader` methods.
+type PictureOptions struct {
+	// A label identifying the image. It is recorded as the image's source in the picturebook's manifest.
+	Label string
+	// The caption associated with the image.
+	Caption string
+	// The long-form text associated with the image, displayed on the page before the image.
+	Text string
+	// An optional value used to override the picturebook's `FillPage` option for this image.
+	FillPage *bool
+}
+
+// AddImage adds 'im' to the picturebook as the next page (or pages, depending on the `EvenOnly`, `OddOnly` and 'opts.Text' values).
+// The image is encoded as a PNG file and held in memory until the picturebook is saved.
+func (pb *PictureBook) AddImage(ctx context.Context, im image.Image, opts *PictureOptions) error {
+
+	// fpdf does not support 16-bit PNG files so convert those images to 8-bit images first
+
+	switch im.(type) {
+	case *image.Gray16, *image.RGBA64, *image.NRGBA64:
+
+		bounds := im.Bounds()
+		new_im := image.NewNRGBA(bounds)
+		draw.Draw(new_im, bounds, im, bounds.Min, draw.Src)
+
+		im = new_im
+	}
+
+	var buf bytes.Buffer
+
+	err := png.Encode(&buf, im)
+
+	if err != nil {
+		return fmt.Errorf("Failed to encode image, %w", err)
+	}
+
+	return pb.AddReader(ctx, &buf, opts)
+}
+
+// AddReader adds the image read from 'r' to the picturebook as the next page (or pages, depending on the `EvenOnly`, `OddOnly` and 'opts.Text' values).
+// The body of 'r' is held in memory until the picturebook is saved. Image formats are handled the same way they are for images
+// added using the `AddPictures` method.
+func (pb *PictureBook) AddReader(ctx context.Context, r io.Reader, opts *PictureOptions) error {
+
+	if opts == nil {
+		opts = &PictureOptions{}
+	}
+
+	b, err := pb.memoryBucket(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	path, err := tempfile.TempFileWithReader(ctx, b, r, "")
+
+	if err != nil {
+		return fmt.Errorf("Failed to store image in memory, %w", err)
+	}
+
+	source := opts.Label
+
+	if source == "" {
+		source = path
+	}
+
+	pic := &picture.PictureBookPicture{
+		Source:   source,
+		Path:     path,
+		Caption:  opts.Caption,
+		Text:     opts.Text,
+		Bucket:   b,
+		FillPage: opts.FillPage,
+	}
+
+	pb.Mutex.Lock()
+	pb.pages += 1
+	pagenum := pb.pages
+	pb.Mutex.Unlock()
+
+	return pb.addPictureAndText(ctx, pagenum, pic)
+}
+
+// memoryBucket returns the in-memory `bucket.Bucket` instance used to store images added using the `AddImage` and `AddReader` methods,
+// creating it if necessary.
+func (pb *PictureBook) memoryBucket(ctx context.Context) (bucket.Bucket, error) {
+
+	pb.Mutex.Lock()
+	defer pb.Mutex.Unlock()
+
+	if pb.memory != nil {
+		return pb.memory, nil
+	}
+
+	// Ensure the mem:// scheme, registered by the gocloud.dev/blob/memblob package, is available
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to register buckets, %w", err)
+	}
+
+	b, err := bucket.NewBucket(ctx, "mem://")
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create in-memory bucket, %w", err)
+	}
+
+	pb.memory = b
+	return pb.memory, nil
+}
@@ -0,0 +1,104 @@
+package picturebook
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aaronland/go-picturebook/picture"
+)
+
+func TestAddImageAndReader(t *testing.T) {
+
+	ctx := context.Background()
+
+	pb, root := newTestPictureBook(t, func(opts *PictureBookOptions) {
+		opts.Manifest = MANIFEST_FORMAT_JSON
+	})
+
+	// A 16-bit image, which fpdf can not read as a PNG file without being converted first
+
+	im := image.NewRGBA64(image.Rect(0, 0, 40, 20))
+	im.Set(10, 10, color.White)
+
+	err := pb.AddImage(ctx, im, &PictureOptions{
+		Label:   "chart",
+		Caption: "A chart",
+		Text:    "Some words about the chart.",
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to add image, %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	err = jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 20, 40)), nil)
+
+	if err != nil {
+		t.Fatalf("Failed to encode JPEG, %v", err)
+	}
+
+	err = pb.AddReader(ctx, &buf, nil)
+
+	if err != nil {
+		t.Fatalf("Failed to add reader, %v", err)
+	}
+
+	pages := pb.Pages()
+
+	expected := []string{
+		picture.PAGE_TYPE_TEXT,
+		picture.PAGE_TYPE_PICTURE,
+		picture.PAGE_TYPE_PICTURE,
+	}
+
+	if len(pages) != len(expected) {
+		t.Fatalf("Unexpected page count: %d", len(pages))
+	}
+
+	for i, page := range pages {
+
+		if page.Type != expected[i] {
+			t.Fatalf("Unexpected type for page %d: %s", i+1, page.Type)
+		}
+	}
+
+	if pages[1].Source != "chart" || pages[1].Caption != "A chart" || pages[1].PixelWidth != 40 {
+		t.Fatalf("Unexpected picture page %v", pages[1])
+	}
+
+	if pages[1].Checksum == "" {
+		t.Fatalf("Missing checksum for picture page")
+	}
+
+	if pages[2].Format != "jpeg" || pages[2].PixelHeight != 40 {
+		t.Fatalf("Unexpected picture page %v", pages[2])
+	}
+
+	err = pb.Save(ctx, "test.pdf")
+
+	if err != nil {
+		t.Fatalf("Failed to save picturebook, %v", err)
+	}
+
+	// Images held in memory are released once the picturebook has been saved
+
+	if pb.memory != nil {
+		t.Fatalf("Expected in-memory images to be removed after saving")
+	}
+
+	for _, fname := range []string{"test.pdf", "test.json"} {
+
+		_, err := os.Stat(filepath.Join(root, fname))
+
+		if err != nil {
+			t.Fatalf("Expected %s to exist, %v", fname, err)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package output
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
+	"github.com/aaronland/go-picturebook/picture"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := RegisterOutput(ctx, "cbz", NewCBZOutput)
+
+	if err != nil {
+		panic(err)
+	}
+
+	err = handler.RegisterDescription(ctx, handler.OUTPUT, &handler.Description{
+		Scheme:  "cbz",
+		Summary: "Write the picturebook as a CBZ (comic book zip) archive.",
+		Syntax:  "cbz://?language={CODE}&manga={BOOLEAN}&max-dimension={INTEGER}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "language",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "string",
+				Description: "An ISO language code to record in the ComicInfo.xml file.",
+			},
+			{
+				Name:        "manga",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "boolean",
+				Description: "A boolean flag signaling that pages should be read from right to left.",
+				Default:     "false",
+			},
+			{
+				Name:        "max-dimension",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "integer",
+				Description: "If greater than zero images whose longest side is larger than this value, in pixels, are downsampled.",
+				Default:     "0",
+			},
+		},
+		Examples: []string{
+			"cbz://",
+			"cbz://?manga=true&max-dimension=2400",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// type CBZOutput implements the `Output` interface to write a picturebook as a CBZ (comic book zip) archive.
+type CBZOutput struct {
+	Output
+	language      string
+	manga         bool
+	max_dimension int
+}
+
+// NewCBZOutput returns a new instance of `CBZOutput` for 'uri' which must be parsable as a valid `net/url` URL instance.
+//
+//	cbz://?{PARAMETERS}
+//
+// Where valid parameters are:
+// * `language` An optional ISO language code recorded in the archive's ComicInfo.xml file.
+// * `manga` A boolean flag signaling that pages should be read from right to left. Default is false.
+// * `max-dimension` If greater than zero images whose longest side is larger than this value, in pixels, are downsampled. Default is 0.
+func NewCBZOutput(ctx context.Context, uri string) (Output, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI for NewCBZOutput, %w", err)
+	}
+
+	q := u.Query()
+
+	manga := false
+	max_dimension := 0
+
+	str_manga := q.Get("manga")
+	str_max := q.Get("max-dimension")
+
+	if str_manga != "" {
+
+		v, err := strconv.ParseBool(str_manga)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse ?manga= parameter, %w", err)
+		}
+
+		manga = v
+	}
+
+	if str_max != "" {
+
+		v, err := strconv.Atoi(str_max)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse ?max-dimension= parameter, %w", err)
+		}
+
+		max_dimension = v
+	}
+
+	o := &CBZOutput{
+		language:      q.Get("language"),
+		manga:         manga,
+		max_dimension: max_dimension,
+	}
+
+	return o, nil
+}
+
+// Extension returns ".cbz".
+func (o *CBZOutput) Extension() string {
+	return ".cbz"
+}
+
+// type ComicInfo defines a struct containing the subset of the ComicInfo.xml (v2.0) schema written to CBZ archives.
+type ComicInfo struct {
+	XMLName  xml.Name `xml:"ComicInfo"`
+	XMLNSXSI string   `xml:"xmlns:xsi,attr"`
+	XMLNSXSD string   `xml:"xmlns:xsd,attr"`
+	// The title of the picturebook.
+	Title string `xml:"Title,omitempty"`
+	// The text of any text pages in the picturebook.
+	Summary string `xml:"Summary,omitempty"`
+	// The number of images in the archive.
+	PageCount int `xml:"PageCount"`
+	// The ISO code of the language of the picturebook.
+	LanguageISO string `xml:"LanguageISO,omitempty"`
+	// Whether the picturebook is a manga. If "YesAndRightToLeft" pages are read from right to left.
+	Manga string `xml:"Manga,omitempty"`
+	// The list of `ComicInfoPage` instances describing each image in the archive.
+	Pages []ComicInfoPage `xml:"Pages>Page"`
+}
+
+// type ComicInfoPage defines a struct containing details about a single image in a CBZ archive.
+type ComicInfoPage struct {
+	// The (zero-based) index of the image in the archive.
+	Image int `xml:"Image,attr"`
+	// The type of page, for example "FrontCover" or "Story".
+	Type string `xml:"Type,attr,omitempty"`
+	// The size, in bytes, of the image.
+	ImageSize int `xml:"ImageSize,attr,omitempty"`
+	// The width, in pixels, of the image.
+	ImageWidth int `xml:"ImageWidth,attr,omitempty"`
+	// The height, in pixels, of the image.
+	ImageHeight int `xml:"ImageHeight,attr,omitempty"`
+	// A label for the image, derived from the title of the section it starts or its caption.
+	Bookmark string `xml:"Bookmark,attr,omitempty"`
+}
+
+// Save writes 'doc' as a CBZ archive to 'path' in 'target'. The final image of each picture page is written to the
+// archive, in page order, with a zero-padded filename followed by a ComicInfo.xml file derived from the title of the
+// picturebook, its text pages and the captions and section titles associated with each image.
+func (o *CBZOutput) Save(ctx context.Context, target bucket.Bucket, path string, doc *Document) error {
+
+	wr, err := target.NewWriter(ctx, path, nil)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create a new writer for %s, %w", path, err)
+	}
+
+	err = o.SaveTo(ctx, wr, doc)
+
+	if err != nil {
+		wr.Close()
+		return fmt.Errorf("Failed to write CBZ archive for %s, %w", path, err)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		return fmt.Errorf("Failed to close writer for %s, %w", path, err)
+	}
+
+	return nil
+}
+
+// SaveTo writes 'doc' as a CBZ archive to 'wr'.
+func (o *CBZOutput) SaveTo(ctx context.Context, wr io.Writer, doc *Document) error {
+
+	count := 0
+
+	for _, p := range doc.Pages {
+		if p.Type == picture.PAGE_TYPE_PICTURE {
+			count += 1
+		}
+	}
+
+	// Filenames are padded to (at least) 4 digits so that they sort correctly in readers that order images by name
+
+	padding := max(4, len(strconv.Itoa(count)))
+
+	info := &ComicInfo{
+		XMLNSXSI:    "http://www.w3.org/2001/XMLSchema-instance",
+		XMLNSXSD:    "http://www.w3.org/2001/XMLSchema",
+		Title:       doc.Title,
+		PageCount:   count,
+		LanguageISO: o.language,
+		Pages:       make([]ComicInfoPage, 0, count),
+	}
+
+	if o.manga {
+		info.Manga = "YesAndRightToLeft"
+	}
+
+	summary := make([]string, 0)
+	section := ""
+
+	zip_wr := zip.NewWriter(wr)
+
+	for _, p := range doc.Pages {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// pass
+		}
+
+		switch p.Type {
+		case picture.PAGE_TYPE_SECTION:
+			section = p.Text
+			continue
+		case picture.PAGE_TYPE_TEXT:
+			summary = append(summary, paragraphs(p.Text)...)
+			continue
+		case picture.PAGE_TYPE_PICTURE:
+			// pass
+		default:
+			continue
+		}
+
+		body, format, err := pageImage(ctx, p, o.max_dimension)
+
+		if err != nil {
+			return err
+		}
+
+		idx := len(info.Pages)
+		name := fmt.Sprintf("%0*d%s", padding, idx+1, imageExtension(format))
+
+		// Images are already compressed so they are stored as-is
+
+		img_wr, err := zip_wr.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Store,
+			Modified: time.Now(),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		_, err = img_wr.Write(body)
+
+		if err != nil {
+			return fmt.Errorf("Failed to write %s, %w", name, err)
+		}
+
+		info_page := ComicInfoPage{
+			Image:     idx,
+			Type:      "Story",
+			ImageSize: len(body),
+			Bookmark:  strings.Join(captionLines(p.Caption), " "),
+		}
+
+		if idx == 0 {
+			info_page.Type = "FrontCover"
+		}
+
+		if section != "" {
+			info_page.Bookmark = section
+			section = ""
+		}
+
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(body))
+
+		if err == nil {
+			info_page.ImageWidth = cfg.Width
+			info_page.ImageHeight = cfg.Height
+		}
+
+		info.Pages = append(info.Pages, info_page)
+	}
+
+	info.Summary = strings.Join(summary, "\n\n")
+
+	info_wr, err := createZipFile(zip_wr, "ComicInfo.xml")
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(info_wr, xml.Header)
+
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(info_wr)
+	enc.Indent("", "  ")
+
+	err = enc.Encode(info)
+
+	if err != nil {
+		return fmt.Errorf("Failed to encode ComicInfo.xml, %w", err)
+	}
+
+	return zip_wr.Close()
+}
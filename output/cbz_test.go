@@ -0,0 +1,78 @@
+package output
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+func TestCBZOutput(t *testing.T) {
+
+	ctx := context.Background()
+
+	b, doc := newTestDocument(ctx, t)
+	defer b.Close()
+
+	o, err := NewOutput(ctx, "cbz://?manga=true")
+
+	if err != nil {
+		t.Fatalf("Failed to create CBZ output, %v", err)
+	}
+
+	err = o.Save(ctx, b, "example.cbz", doc)
+
+	if err != nil {
+		t.Fatalf("Failed to save CBZ archive, %v", err)
+	}
+
+	r, err := b.NewReader(ctx, "example.cbz", nil)
+
+	if err != nil {
+		t.Fatalf("Failed to open CBZ archive, %v", err)
+	}
+
+	defer r.Close()
+
+	body, err := io.ReadAll(r)
+
+	if err != nil {
+		t.Fatalf("Failed to read CBZ archive, %v", err)
+	}
+
+	zip_r, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+
+	if err != nil {
+		t.Fatalf("Failed to open CBZ archive as zip archive, %v", err)
+	}
+
+	if len(zip_r.File) != 2 || zip_r.File[0].Name != "0001.png" || zip_r.File[1].Name != "ComicInfo.xml" {
+		t.Fatalf("Unexpected files in CBZ archive")
+	}
+
+	info_r, err := zip_r.File[1].Open()
+
+	if err != nil {
+		t.Fatalf("Failed to open ComicInfo.xml, %v", err)
+	}
+
+	defer info_r.Close()
+
+	var info *ComicInfo
+
+	err = xml.NewDecoder(info_r).Decode(&info)
+
+	if err != nil {
+		t.Fatalf("Failed to decode ComicInfo.xml, %v", err)
+	}
+
+	if info.Title != doc.Title || info.PageCount != 1 || info.Manga != "YesAndRightToLeft" || info.Summary != "One.\n\nTwo." {
+		t.Fatalf("Unexpected ComicInfo.xml properties")
+	}
+
+	if len(info.Pages) != 1 || info.Pages[0].Bookmark != "Part One" || info.Pages[0].ImageWidth != 40 || info.Pages[0].Type != "FrontCover" {
+		t.Fatalf("Unexpected ComicInfo.xml pages")
+	}
+}
@@ -0,0 +1,555 @@
+package output
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
+	"github.com/aaronland/go-picturebook/picture"
+	"github.com/google/uuid"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := RegisterOutput(ctx, "epub", NewEPUBOutput)
+
+	if err != nil {
+		panic(err)
+	}
+
+	err = handler.RegisterDescription(ctx, handler.OUTPUT, &handler.Description{
+		Scheme:  "epub",
+		Summary: "Write the picturebook as an EPUB 3 fixed-layout (pre-paginated) book.",
+		Syntax:  "epub://?language={CODE}&max-dimension={INTEGER}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "language",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "string",
+				Description: "The language code of the book.",
+				Default:     "en",
+			},
+			{
+				Name:        "max-dimension",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "integer",
+				Description: "If greater than zero images whose longest side is larger than this value, in pixels, are downsampled.",
+				Default:     "0",
+			},
+		},
+		Examples: []string{
+			"epub://",
+			"epub://?language=fr&max-dimension=1600",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// cssPixelsPerInch is the number of CSS pixels in an inch, used to convert page dimensions to fixed-layout viewports.
+const cssPixelsPerInch float64 = 96.0
+
+// type EPUBOutput implements the `Output` interface to write a picturebook as an EPUB 3 fixed-layout (pre-paginated) book.
+type EPUBOutput struct {
+	Output
+	language      string
+	max_dimension int
+}
+
+// NewEPUBOutput returns a new instance of `EPUBOutput` for 'uri' which must be parsable as a valid `net/url` URL instance.
+//
+//	epub://?{PARAMETERS}
+//
+// Where valid parameters are:
+// * `language` The language code of the book. Default is "en".
+// * `max-dimension` If greater than zero images whose longest side is larger than this value, in pixels, are downsampled. Default is 0.
+func NewEPUBOutput(ctx context.Context, uri string) (Output, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI for NewEPUBOutput, %w", err)
+	}
+
+	q := u.Query()
+
+	language := "en"
+	max_dimension := 0
+
+	if q.Has("language") {
+		language = q.Get("language")
+	}
+
+	str_max := q.Get("max-dimension")
+
+	if str_max != "" {
+
+		v, err := strconv.Atoi(str_max)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse ?max-dimension= parameter, %w", err)
+		}
+
+		max_dimension = v
+	}
+
+	o := &EPUBOutput{
+		language:      language,
+		max_dimension: max_dimension,
+	}
+
+	return o, nil
+}
+
+// Extension returns ".epub".
+func (o *EPUBOutput) Extension() string {
+	return ".epub"
+}
+
+// type epubItem defines a struct containing details about a resource listed in an EPUB package document.
+type epubItem struct {
+	ID         string
+	Href       string
+	MediaType  string
+	Properties string
+}
+
+// type epubPage defines a struct containing the details used to render a single page of an EPUB book.
+type epubPage struct {
+	ID         string
+	Href       string
+	Type       string
+	Number     int
+	Name       string
+	Language   string
+	Title      string
+	Width      int
+	Height     int
+	Image      string
+	X          int
+	Y          int
+	ImageW     int
+	ImageH     int
+	Caption    []string
+	Paragraphs []string
+}
+
+// Save writes 'doc' as an EPUB 3 fixed-layout book to 'path' in 'target'. Each page in 'doc' is written as its own XHTML
+// document: pictures are positioned as they are in the equivalent PDF document, with captions as figcaptions, and text and
+// section pages are written as chapters.
+func (o *EPUBOutput) Save(ctx context.Context, target bucket.Bucket, path string, doc *Document) error {
+
+	wr, err := target.NewWriter(ctx, path, nil)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create a new writer for %s, %w", path, err)
+	}
+
+	err = o.SaveTo(ctx, wr, doc)
+
+	if err != nil {
+		wr.Close()
+		return fmt.Errorf("Failed to write EPUB book for %s, %w", path, err)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		return fmt.Errorf("Failed to close writer for %s, %w", path, err)
+	}
+
+	return nil
+}
+
+// SaveTo writes 'doc' as an EPUB 3 fixed-layout book to 'wr'.
+func (o *EPUBOutput) SaveTo(ctx context.Context, wr io.Writer, doc *Document) error {
+
+	zip_wr := zip.NewWriter(wr)
+
+	// The mimetype file must be the first file in the archive and must not be compressed
+
+	mimetype_wr, err := zip_wr.CreateHeader(&zip.FileHeader{
+		Name:     "mimetype",
+		Method:   zip.Store,
+		Modified: time.Now(),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(mimetype_wr, "application/epub+zip")
+
+	if err != nil {
+		return err
+	}
+
+	err = writeZipFile(zip_wr, "META-INF/container.xml", epubContainer)
+
+	if err != nil {
+		return err
+	}
+
+	err = writeZipFile(zip_wr, "OEBPS/style.css", epubStylesheet)
+
+	if err != nil {
+		return err
+	}
+
+	width := int(doc.Width * cssPixelsPerInch)
+	height := int(doc.Height * cssPixelsPerInch)
+
+	items := make([]*epubItem, 0)
+	pages := make([]*epubPage, len(doc.Pages))
+
+	has_cover := false
+
+	for i, p := range doc.Pages {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// pass
+		}
+
+		id := fmt.Sprintf("page-%04d", p.Page)
+
+		pg := &epubPage{
+			ID:       id,
+			Href:     fmt.Sprintf("pages/%s.xhtml", id),
+			Type:     p.Type,
+			Number:   p.Page,
+			Name:     pageLabel(p),
+			Language: o.language,
+			Title:    doc.Title,
+			Width:    width,
+			Height:   height,
+		}
+
+		switch p.Type {
+		case picture.PAGE_TYPE_PICTURE:
+
+			body, format, err := pageImage(ctx, p, o.max_dimension)
+
+			if err != nil {
+				return err
+			}
+
+			image_id := fmt.Sprintf("image-%04d", p.Page)
+			image_href := fmt.Sprintf("images/%s%s", image_id, imageExtension(format))
+
+			err = writeZipFile(zip_wr, "OEBPS/"+image_href, string(body))
+
+			if err != nil {
+				return err
+			}
+
+			image_item := &epubItem{
+				ID:        image_id,
+				Href:      image_href,
+				MediaType: imageMediaType(format),
+			}
+
+			if !has_cover {
+				image_item.Properties = "cover-image"
+				has_cover = true
+			}
+
+			items = append(items, image_item)
+
+			pg.Image = "../" + image_href
+			pg.X = int(p.X * cssPixelsPerInch)
+			pg.Y = int(p.Y * cssPixelsPerInch)
+			pg.ImageW = int(p.Width * cssPixelsPerInch)
+			pg.ImageH = int(p.Height * cssPixelsPerInch)
+			pg.Caption = captionLines(p.Caption)
+
+		case picture.PAGE_TYPE_TEXT:
+			pg.Paragraphs = paragraphs(p.Text)
+		}
+
+		page_wr, err := createZipFile(zip_wr, "OEBPS/"+pg.Href)
+
+		if err != nil {
+			return err
+		}
+
+		err = epubTemplates.ExecuteTemplate(page_wr, "page", pg)
+
+		if err != nil {
+			return fmt.Errorf("Failed to render page %d, %w", p.Page, err)
+		}
+
+		items = append(items, &epubItem{
+			ID:        id,
+			Href:      pg.Href,
+			MediaType: "application/xhtml+xml",
+		})
+
+		pages[i] = pg
+	}
+
+	// The table of contents lists each section; if there are no sections then it lists every page that isn't blank
+
+	toc := make([]*epubPage, 0)
+
+	for _, pg := range pages {
+		if pg.Type == picture.PAGE_TYPE_SECTION {
+			toc = append(toc, pg)
+		}
+	}
+
+	if len(toc) == 0 {
+
+		for _, pg := range pages {
+
+			if pg.Type != picture.PAGE_TYPE_BLANK {
+				toc = append(toc, pg)
+			}
+		}
+	}
+
+	nav_wr, err := createZipFile(zip_wr, "OEBPS/nav.xhtml")
+
+	if err != nil {
+		return err
+	}
+
+	nav_vars := map[string]any{
+		"Language": o.language,
+		"Title":    doc.Title,
+		"TOC":      toc,
+		"Pages":    pages,
+	}
+
+	err = epubTemplates.ExecuteTemplate(nav_wr, "nav", nav_vars)
+
+	if err != nil {
+		return fmt.Errorf("Failed to render navigation document, %w", err)
+	}
+
+	opf_wr, err := createZipFile(zip_wr, "OEBPS/package.opf")
+
+	if err != nil {
+		return err
+	}
+
+	opf_vars := map[string]any{
+		"Identifier": uuid.New().String(),
+		"Language":   o.language,
+		"Title":      doc.Title,
+		"Modified":   time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"Items":      items,
+		"Pages":      pages,
+	}
+
+	err = epubTemplates.ExecuteTemplate(opf_wr, "package", opf_vars)
+
+	if err != nil {
+		return fmt.Errorf("Failed to render package document, %w", err)
+	}
+
+	return zip_wr.Close()
+}
+
+// createZipFile adds a new (compressed) file named 'name' to 'zip_wr' returning a writer for its contents.
+func createZipFile(zip_wr *zip.Writer, name string) (io.Writer, error) {
+
+	hdr := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	}
+
+	return zip_wr.CreateHeader(hdr)
+}
+
+// writeZipFile writes 'body' to a new file named 'name' in 'zip_wr'.
+func writeZipFile(zip_wr *zip.Writer, name string, body string) error {
+
+	wr, err := createZipFile(zip_wr, name)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(wr, body)
+	return err
+}
+
+// pageLabel returns a short, human-readable label for 'p' derived from its caption, its source image or its page number.
+func pageLabel(p *picture.PictureBookPage) string {
+
+	switch {
+	case p.Caption != "":
+		return strings.Join(captionLines(p.Caption), " ")
+	case p.Type == picture.PAGE_TYPE_SECTION && p.Text != "":
+		return p.Text
+	case p.Source != "":
+		return filepath.Base(strings.Split(p.Source, "#")[0])
+	default:
+		return fmt.Sprintf("Page %d", p.Page)
+	}
+}
+
+// captionLines returns the non-empty lines in 'caption'.
+func captionLines(caption string) []string {
+
+	lines := make([]string, 0)
+
+	for ln := range strings.SplitSeq(caption, "\n") {
+
+		ln = strings.TrimSpace(ln)
+
+		if ln != "" {
+			lines = append(lines, ln)
+		}
+	}
+
+	return lines
+}
+
+// paragraphs returns the paragraphs, separated by one or more blank lines, in 'text'.
+func paragraphs(text string) []string {
+
+	paras := make([]string, 0)
+
+	for p := range strings.SplitSeq(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+
+		p = strings.TrimSpace(p)
+
+		if p != "" {
+			paras = append(paras, p)
+		}
+	}
+
+	return paras
+}
+
+// escapeXML returns 's' with any XML special characters escaped.
+func escapeXML(s string) string {
+
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+
+	return sb.String()
+}
+
+const epubContainer string = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/package.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const epubStylesheet string = `html, body { margin: 0; padding: 0; }
+body { position: relative; overflow: hidden; background-color: #fff; font-family: Helvetica, Arial, sans-serif; }
+figure.picture { position: absolute; margin: 0; padding: 0; }
+figure.picture img { display: block; }
+figure.picture figcaption { margin-top: 0.1in; text-align: right; font-size: 8pt; color: rgb(128, 128, 128); }
+section.text { box-sizing: border-box; height: 100%; padding: 1in; font-size: 12pt; }
+section.section { display: flex; box-sizing: border-box; height: 100%; align-items: center; justify-content: center; }
+section.section h1 { font-size: 16pt; font-weight: normal; text-align: center; }
+`
+
+var epubTemplates = template.Must(template.New("epub").Funcs(template.FuncMap{
+	"escape": escapeXML,
+}).Parse(`{{ define "page" }}<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{ escape .Language }}" xml:lang="{{ escape .Language }}">
+  <head>
+    <meta charset="UTF-8"/>
+    <title>{{ escape .Title }}</title>
+    <meta name="viewport" content="width={{ .Width }}, height={{ .Height }}"/>
+    <link rel="stylesheet" type="text/css" href="../style.css"/>
+  </head>
+  <body style="width: {{ .Width }}px; height: {{ .Height }}px;">
+{{- if eq .Type "picture" }}
+    <figure class="picture" style="left: {{ .X }}px; top: {{ .Y }}px; width: {{ .ImageW }}px;">
+      <img src="{{ escape .Image }}" alt="{{ escape .Name }}" style="width: {{ .ImageW }}px; height: {{ .ImageH }}px;"/>
+{{- if .Caption }}
+      <figcaption>{{ range $i, $ln := .Caption }}{{ if $i }}<br/>{{ end }}{{ escape $ln }}{{ end }}</figcaption>
+{{- end }}
+    </figure>
+{{- else if eq .Type "text" }}
+    <section epub:type="chapter" class="text">
+{{- range .Paragraphs }}
+      <p>{{ escape . }}</p>
+{{- end }}
+    </section>
+{{- else if eq .Type "section" }}
+    <section epub:type="chapter" class="section">
+      <h1>{{ escape .Name }}</h1>
+    </section>
+{{- end }}
+  </body>
+</html>
+{{ end }}
+
+{{ define "nav" }}<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{ escape .Language }}" xml:lang="{{ escape .Language }}">
+  <head>
+    <meta charset="UTF-8"/>
+    <title>{{ escape .Title }}</title>
+  </head>
+  <body>
+    <nav epub:type="toc" id="toc">
+      <h1>{{ escape .Title }}</h1>
+      <ol>
+{{- range .TOC }}
+        <li><a href="{{ .Href }}">{{ escape .Name }}</a></li>
+{{- end }}
+      </ol>
+    </nav>
+    <nav epub:type="page-list" hidden="hidden">
+      <ol>
+{{- range .Pages }}
+        <li><a href="{{ .Href }}">{{ .Number }}</a></li>
+{{- end }}
+      </ol>
+    </nav>
+  </body>
+</html>
+{{ end }}
+
+{{ define "package" }}<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="{{ escape .Language }}">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">urn:uuid:{{ .Identifier }}</dc:identifier>
+    <dc:title>{{ escape .Title }}</dc:title>
+    <dc:language>{{ escape .Language }}</dc:language>
+    <meta property="dcterms:modified">{{ .Modified }}</meta>
+    <meta property="rendition:layout">pre-paginated</meta>
+    <meta property="rendition:orientation">auto</meta>
+    <meta property="rendition:spread">none</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+{{- range .Items }}
+    <item id="{{ .ID }}" href="{{ .Href }}" media-type="{{ .MediaType }}"{{ if .Properties }} properties="{{ .Properties }}"{{ end }}/>
+{{- end }}
+  </manifest>
+  <spine>
+{{- range .Pages }}
+    <itemref idref="{{ .ID }}"/>
+{{- end }}
+  </spine>
+</package>
+{{ end }}`))
@@ -0,0 +1,101 @@
+package output
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEPUBOutput(t *testing.T) {
+
+	ctx := context.Background()
+
+	b, doc := newTestDocument(ctx, t)
+	defer b.Close()
+
+	o, err := NewOutput(ctx, "epub://?max-dimension=10")
+
+	if err != nil {
+		t.Fatalf("Failed to create EPUB output, %v", err)
+	}
+
+	err = o.Save(ctx, b, "example.epub", doc)
+
+	if err != nil {
+		t.Fatalf("Failed to save EPUB book, %v", err)
+	}
+
+	r, err := b.NewReader(ctx, "example.epub", nil)
+
+	if err != nil {
+		t.Fatalf("Failed to open EPUB book, %v", err)
+	}
+
+	defer r.Close()
+
+	body, err := io.ReadAll(r)
+
+	if err != nil {
+		t.Fatalf("Failed to read EPUB book, %v", err)
+	}
+
+	zip_r, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+
+	if err != nil {
+		t.Fatalf("Failed to open EPUB book as zip archive, %v", err)
+	}
+
+	if zip_r.File[0].Name != "mimetype" || zip_r.File[0].Method != zip.Store {
+		t.Fatalf("Expected first file to be an uncompressed mimetype file")
+	}
+
+	contents := make(map[string]string)
+
+	for _, f := range zip_r.File {
+
+		fh, err := f.Open()
+
+		if err != nil {
+			t.Fatalf("Failed to open %s, %v", f.Name, err)
+		}
+
+		v, err := io.ReadAll(fh)
+		fh.Close()
+
+		if err != nil {
+			t.Fatalf("Failed to read %s, %v", f.Name, err)
+		}
+
+		contents[f.Name] = string(v)
+	}
+
+	// The image is larger than ?max-dimension=10 so it should have been downsampled to a JPEG
+
+	expected := map[string]string{
+		"OEBPS/package.opf":           `<meta property="rendition:layout">pre-paginated</meta>`,
+		"OEBPS/nav.xhtml":             `<a href="pages/page-0001.xhtml">Part One</a>`,
+		"OEBPS/pages/page-0002.xhtml": `<figcaption>A &lt;caption&gt;</figcaption>`,
+		"OEBPS/pages/page-0003.xhtml": `<p>Two.</p>`,
+		"OEBPS/images/image-0002.jpg": "",
+	}
+
+	for name, str := range expected {
+
+		v, ok := contents[name]
+
+		if !ok {
+			t.Fatalf("Missing %s", name)
+		}
+
+		if !strings.Contains(v, str) {
+			t.Fatalf("Expected %s to contain '%s'", name, str)
+		}
+	}
+
+	if !strings.Contains(contents["OEBPS/package.opf"], "<dc:title>Example &amp; Co</dc:title>") {
+		t.Fatalf("Unexpected title in package document")
+	}
+}
@@ -0,0 +1,366 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strconv"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
+	"github.com/aaronland/go-picturebook/picture"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := RegisterOutput(ctx, "html", NewHTMLOutput)
+
+	if err != nil {
+		panic(err)
+	}
+
+	err = handler.RegisterDescription(ctx, handler.OUTPUT, &handler.Description{
+		Scheme:  "html",
+		Summary: "Write the picturebook as a static HTML gallery.",
+		Syntax:  "html://?max-dimension={INTEGER}&thumbnail-dimension={INTEGER}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "max-dimension",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "integer",
+				Description: "The maximum size, in pixels, of the longest side of the image displayed on each picture page.",
+				Default:     "1600",
+			},
+			{
+				Name:        "thumbnail-dimension",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "integer",
+				Description: "The maximum size, in pixels, of the longest side of the thumbnail images displayed on the index page.",
+				Default:     "320",
+			},
+		},
+		Examples: []string{
+			"html://",
+			"html://?thumbnail-dimension=240",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// type HTMLOutput implements the `Output` interface to write a picturebook as a static HTML gallery.
+type HTMLOutput struct {
+	Output
+	max_dimension       int
+	thumbnail_dimension int
+}
+
+// NewHTMLOutput returns a new instance of `HTMLOutput` for 'uri' which must be parsable as a valid `net/url` URL instance.
+//
+//	html://?{PARAMETERS}
+//
+// Where valid parameters are:
+// * `max-dimension` The maximum size, in pixels, of the longest side of the image displayed on each picture page. Default is 1600.
+// * `thumbnail-dimension` The maximum size, in pixels, of the longest side of the thumbnail images displayed on the index page. Default is 320.
+func NewHTMLOutput(ctx context.Context, uri string) (Output, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI for NewHTMLOutput, %w", err)
+	}
+
+	q := u.Query()
+
+	max_dimension := 1600
+	thumbnail_dimension := 320
+
+	str_max := q.Get("max-dimension")
+	str_thumbnail := q.Get("thumbnail-dimension")
+
+	if str_max != "" {
+
+		v, err := strconv.Atoi(str_max)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse ?max-dimension= parameter, %w", err)
+		}
+
+		max_dimension = v
+	}
+
+	if str_thumbnail != "" {
+
+		v, err := strconv.Atoi(str_thumbnail)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse ?thumbnail-dimension= parameter, %w", err)
+		}
+
+		thumbnail_dimension = v
+	}
+
+	o := &HTMLOutput{
+		max_dimension:       max_dimension,
+		thumbnail_dimension: thumbnail_dimension,
+	}
+
+	return o, nil
+}
+
+// Extension returns an empty string since the HTML output writes a folder of files.
+func (o *HTMLOutput) Extension() string {
+	return ""
+}
+
+// type htmlPicture defines a struct containing the details used to render a picture in an HTML gallery.
+type htmlPicture struct {
+	Number     int
+	Href       string
+	Name       string
+	Image      string
+	Thumbnail  string
+	Caption    []string
+	Paragraphs []string
+	Previous   *htmlPicture
+	Next       *htmlPicture
+}
+
+// type htmlGroup defines a struct containing a list of pictures, and the title of the section they belong to, listed on
+// the index page of an HTML gallery.
+type htmlGroup struct {
+	Section  string
+	Pictures []*htmlPicture
+}
+
+// Save writes 'doc' as a static HTML gallery to the folder 'path' in 'target'. The gallery consists of an index
+// page listing each picture (and section) in the same order as the equivalent PDF document and one page per picture,
+// with its caption and text and links to the previous and next pictures. Resized copies of each image are written
+// to the "images" and "thumbnails" subfolders.
+func (o *HTMLOutput) Save(ctx context.Context, target bucket.Bucket, path string, doc *Document) error {
+
+	groups := []*htmlGroup{
+		{},
+	}
+
+	pictures := make([]*htmlPicture, 0)
+
+	var previous *htmlPicture
+
+	for _, p := range doc.Pages {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// pass
+		}
+
+		switch p.Type {
+		case picture.PAGE_TYPE_SECTION:
+			groups = append(groups, &htmlGroup{Section: p.Text})
+			continue
+		case picture.PAGE_TYPE_PICTURE:
+			// pass
+		default:
+			continue
+		}
+
+		image_body, image_format, err := pageImage(ctx, p, o.max_dimension)
+
+		if err != nil {
+			return err
+		}
+
+		thumbnail_body, thumbnail_format, err := pageImage(ctx, p, o.thumbnail_dimension)
+
+		if err != nil {
+			return err
+		}
+
+		image_path := fmt.Sprintf("images/%04d%s", p.Page, imageExtension(image_format))
+		thumbnail_path := fmt.Sprintf("thumbnails/%04d%s", p.Page, imageExtension(thumbnail_format))
+
+		err = writeBucketFile(ctx, target, path+"/"+image_path, image_body)
+
+		if err != nil {
+			return err
+		}
+
+		err = writeBucketFile(ctx, target, path+"/"+thumbnail_path, thumbnail_body)
+
+		if err != nil {
+			return err
+		}
+
+		pic := &htmlPicture{
+			Number:     p.Page,
+			Href:       fmt.Sprintf("%04d.html", p.Page),
+			Name:       pageLabel(p),
+			Image:      image_path,
+			Thumbnail:  thumbnail_path,
+			Caption:    captionLines(p.Caption),
+			Paragraphs: paragraphs(p.Text),
+			Previous:   previous,
+		}
+
+		if previous != nil {
+			previous.Next = pic
+		}
+
+		previous = pic
+
+		g := groups[len(groups)-1]
+		g.Pictures = append(g.Pictures, pic)
+
+		pictures = append(pictures, pic)
+	}
+
+	for _, pic := range pictures {
+
+		vars := map[string]any{
+			"Title":   doc.Title,
+			"Picture": pic,
+		}
+
+		err := writeBucketTemplate(ctx, target, path+"/pages/"+pic.Href, "picture", vars)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	index_vars := map[string]any{
+		"Title":  doc.Title,
+		"Groups": groups,
+	}
+
+	err := writeBucketTemplate(ctx, target, path+"/index.html", "index", index_vars)
+
+	if err != nil {
+		return err
+	}
+
+	return writeBucketFile(ctx, target, path+"/style.css", []byte(htmlStylesheet))
+}
+
+// writeBucketTemplate renders the HTML template named 'name' with 'vars' and writes the result to 'path' in 'target'.
+func writeBucketTemplate(ctx context.Context, target bucket.Bucket, path string, name string, vars any) error {
+
+	var buf bytes.Buffer
+
+	err := htmlTemplates.ExecuteTemplate(&buf, name, vars)
+
+	if err != nil {
+		return fmt.Errorf("Failed to render %s, %w", path, err)
+	}
+
+	return writeBucketFile(ctx, target, path, buf.Bytes())
+}
+
+// writeBucketFile writes 'body' to 'path' in 'target'.
+func writeBucketFile(ctx context.Context, target bucket.Bucket, path string, body []byte) error {
+
+	wr, err := target.NewWriter(ctx, path, nil)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create a new writer for %s, %w", path, err)
+	}
+
+	_, err = wr.Write(body)
+
+	if err != nil {
+		wr.Close()
+		return fmt.Errorf("Failed to write %s, %w", path, err)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		return fmt.Errorf("Failed to close writer for %s, %w", path, err)
+	}
+
+	return nil
+}
+
+const htmlStylesheet string = `body { margin: 0 auto; padding: 1em; max-width: 1200px; font-family: Helvetica, Arial, sans-serif; color: #333; }
+a { color: inherit; }
+h1 a { text-decoration: none; }
+ol.gallery { display: flex; flex-wrap: wrap; gap: 1em; margin: 0 0 2em 0; padding: 0; list-style: none; }
+ol.gallery li { width: 320px; }
+ol.gallery img { display: block; max-width: 100%; height: auto; }
+ol.gallery .caption { font-size: small; color: rgb(128, 128, 128); }
+figure.picture { margin: 0 0 1em 0; }
+figure.picture img { display: block; max-width: 100%; height: auto; margin: 0 auto; }
+figure.picture figcaption { margin-top: 0.5em; text-align: right; font-size: small; color: rgb(128, 128, 128); }
+nav.pagination { display: flex; justify-content: space-between; margin: 1em 0; }
+`
+
+var htmlTemplates = template.Must(template.New("html").Parse(`{{ define "index" }}<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8"/>
+    <meta name="viewport" content="width=device-width, initial-scale=1"/>
+    <title>{{ .Title }}</title>
+    <link rel="stylesheet" type="text/css" href="style.css"/>
+  </head>
+  <body>
+    <h1>{{ .Title }}</h1>
+{{- range .Groups }}
+{{- if .Section }}
+    <h2>{{ .Section }}</h2>
+{{- end }}
+{{- if .Pictures }}
+    <ol class="gallery">
+{{- range .Pictures }}
+      <li>
+        <a href="pages/{{ .Href }}"><img src="{{ .Thumbnail }}" alt="{{ .Name }}" loading="lazy"/></a>
+{{- if .Caption }}
+        <div class="caption">{{ range $i, $ln := .Caption }}{{ if $i }}<br/>{{ end }}{{ $ln }}{{ end }}</div>
+{{- end }}
+      </li>
+{{- end }}
+    </ol>
+{{- end }}
+{{- end }}
+  </body>
+</html>
+{{ end }}
+
+{{ define "pagination" }}
+    <nav class="pagination">
+      <span>{{ if .Previous }}<a href="{{ .Previous.Href }}" rel="prev">&larr; Previous</a>{{ end }}</span>
+      <span>Page {{ .Number }}</span>
+      <span>{{ if .Next }}<a href="{{ .Next.Href }}" rel="next">Next &rarr;</a>{{ end }}</span>
+    </nav>
+{{- end }}
+
+{{ define "picture" }}<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8"/>
+    <meta name="viewport" content="width=device-width, initial-scale=1"/>
+    <title>{{ .Title }} – {{ .Picture.Name }}</title>
+    <link rel="stylesheet" type="text/css" href="../style.css"/>
+  </head>
+  <body>
+    <h1><a href="../index.html">{{ .Title }}</a></h1>
+{{- template "pagination" .Picture }}
+    <figure class="picture">
+      <img src="../{{ .Picture.Image }}" alt="{{ .Picture.Name }}"/>
+{{- if .Picture.Caption }}
+      <figcaption>{{ range $i, $ln := .Picture.Caption }}{{ if $i }}<br/>{{ end }}{{ $ln }}{{ end }}</figcaption>
+{{- end }}
+    </figure>
+{{- range .Picture.Paragraphs }}
+    <p>{{ . }}</p>
+{{- end }}
+{{- template "pagination" .Picture }}
+  </body>
+</html>
+{{ end }}`))
@@ -0,0 +1,59 @@
+package output
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestHTMLOutput(t *testing.T) {
+
+	ctx := context.Background()
+
+	b, doc := newTestDocument(ctx, t)
+	defer b.Close()
+
+	o, err := NewOutput(ctx, "html://?thumbnail-dimension=10")
+
+	if err != nil {
+		t.Fatalf("Failed to create HTML output, %v", err)
+	}
+
+	err = o.Save(ctx, b, "gallery", doc)
+
+	if err != nil {
+		t.Fatalf("Failed to save HTML gallery, %v", err)
+	}
+
+	// The image is smaller than the default ?max-dimension= so it is copied as-is but the
+	// thumbnail is larger than ?thumbnail-dimension=10 so it should have been resized
+
+	expected := map[string]string{
+		"gallery/index.html":          `<a href="pages/0002.html"><img src="thumbnails/0002.jpg" alt="A &lt;caption&gt;" loading="lazy"/></a>`,
+		"gallery/pages/0002.html":     `<img src="../images/0002.png" alt="A &lt;caption&gt;"/>`,
+		"gallery/images/0002.png":     "",
+		"gallery/thumbnails/0002.jpg": "",
+		"gallery/style.css":           "",
+	}
+
+	for path, str := range expected {
+
+		r, err := b.NewReader(ctx, path, nil)
+
+		if err != nil {
+			t.Fatalf("Failed to open %s, %v", path, err)
+		}
+
+		body, err := io.ReadAll(r)
+		r.Close()
+
+		if err != nil {
+			t.Fatalf("Failed to read %s, %v", path, err)
+		}
+
+		if !strings.Contains(string(body), str) {
+			t.Fatalf("Expected %s to contain '%s'", path, str)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+
+	"github.com/aaronland/go-image/v2/encode"
+	"github.com/aaronland/go-picturebook/picture"
+	"golang.org/x/image/draw"
+)
+
+// pageImage returns the body of the final image placed on 'page' and its (short) format name. If 'max_dimension' is greater
+// than zero and either side of the image is larger than 'max_dimension' the image is downsampled, so that its longest side
+// is equal to 'max_dimension', and encoded as a JPEG.
+func pageImage(ctx context.Context, page *picture.PictureBookPage, max_dimension int) ([]byte, string, error) {
+
+	if page.Bucket == nil || page.Image == "" {
+		return nil, "", fmt.Errorf("Page %d is missing an image", page.Page)
+	}
+
+	r, err := page.Bucket.NewReader(ctx, page.Image, nil)
+
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to create new reader for %s, %w", page.Image, err)
+	}
+
+	defer r.Close()
+
+	body, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to read %s, %w", page.Image, err)
+	}
+
+	if max_dimension <= 0 || max(page.PixelWidth, page.PixelHeight) <= max_dimension {
+		return body, page.Format, nil
+	}
+
+	im, _, err := image.Decode(bytes.NewReader(body))
+
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to decode %s, %w", page.Image, err)
+	}
+
+	im = resizeImage(im, max_dimension)
+
+	var buf bytes.Buffer
+
+	err = encode.EncodeJPEG(ctx, &buf, im, nil, nil)
+
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to encode %s, %w", page.Image, err)
+	}
+
+	return buf.Bytes(), "jpeg", nil
+}
+
+// resizeImage returns a copy of 'im' scaled so that its longest side is equal to 'max_dimension'. If neither side
+// of 'im' is larger than 'max_dimension' it is returned as-is.
+func resizeImage(im image.Image, max_dimension int) image.Image {
+
+	bounds := im.Bounds()
+
+	w := bounds.Dx()
+	h := bounds.Dy()
+
+	if max(w, h) <= max_dimension {
+		return im
+	}
+
+	if w >= h {
+		h = max(1, h*max_dimension/w)
+		w = max_dimension
+	} else {
+		w = max(1, w*max_dimension/h)
+		h = max_dimension
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), im, bounds, draw.Over, nil)
+
+	return dst
+}
+
+// imageExtension returns the filename extension, including the leading ".", for the image format 'format'.
+func imageExtension(format string) string {
+
+	switch format {
+	case "jpeg", "jpg":
+		return ".jpg"
+	default:
+		return "." + format
+	}
+}
+
+// imageMediaType returns the media (MIME) type for the image format 'format'.
+func imageMediaType(format string) string {
+
+	switch format {
+	case "jpg":
+		return "image/jpeg"
+	default:
+		return "image/" + format
+	}
+}
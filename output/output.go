@@ -0,0 +1,113 @@
+// package output provides a common interface for writing a picturebook in formats other than PDF.
+package output
+
+import (
+	"context"
+	"io"
+	"net/url"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/picture"
+	"github.com/aaronland/go-roster"
+)
+
+// type Document defines a struct containing the details of a picturebook to be written by an `Output` instance.
+type Document struct {
+	// The title of the picturebook.
+	Title string
+	// The width, in inches, of each page in the picturebook (including any bleed).
+	Width float64
+	// The height, in inches, of each page in the picturebook (including any bleed).
+	Height float64
+	// The number dots per inch used to calculate the size of the picturebook.
+	DPI float64
+	// The list of `picture.PictureBookPage` instances describing each of the pages in the picturebook, in order.
+	Pages []*picture.PictureBookPage
+}
+
+// type Output provides a common interface for writing a picturebook in a specific format.
+type Output interface {
+	// Save writes the `Document` to a path in a `bucket.Bucket` instance.
+	Save(context.Context, bucket.Bucket, string, *Document) error
+	// Extension returns the filename extension, including the leading ".", for picturebooks written by the output.
+	// An empty string indicates that the output writes a folder of files rather than a single file.
+	Extension() string
+}
+
+// type WriterOutput provides an interface for `Output` instances which write a picturebook as a single file and can
+// write that file to an arbitrary `io.Writer` instance.
+type WriterOutput interface {
+	Output
+	// SaveTo writes the `Document` to an `io.Writer` instance.
+	SaveTo(context.Context, io.Writer, *Document) error
+}
+
+// type OutputInitializeFunc defined a common initialization function for instances implementing the Output interface.
+// This is specified when the packages definining those instances call `RegisterOutput` and invoked with the `NewOutput`
+// method is called.
+type OutputInitializeFunc func(context.Context, string) (Output, error)
+
+var outputs roster.Roster
+
+func ensureRoster() error {
+
+	if outputs == nil {
+
+		r, err := roster.NewDefaultRoster()
+
+		if err != nil {
+			return err
+		}
+
+		outputs = r
+	}
+
+	return nil
+}
+
+// RegisterOutput associates a URI scheme with a `OutputInitializeFunc` initialization function.
+func RegisterOutput(ctx context.Context, name string, fn OutputInitializeFunc) error {
+
+	err := ensureRoster()
+
+	if err != nil {
+		return err
+	}
+
+	return outputs.Register(ctx, name, fn)
+}
+
+// NewOutput returns a new `Output` instance for 'uri' whose scheme is expected to have been associated
+// with an `OutputInitializeFunc` (by the `RegisterOutput` method.
+func NewOutput(ctx context.Context, uri string) (Output, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := u.Scheme
+
+	i, err := outputs.Driver(ctx, scheme)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fn := i.(OutputInitializeFunc)
+
+	o, err := fn(ctx, uri)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// AvailableOutputs returns the list of schemes that have been registered with `OutputInitializeFunc` functions.
+func AvailableOutputs() []string {
+	ctx := context.Background()
+	return outputs.Drivers(ctx)
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/picture"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+// newTestDocument returns a new bucket, in a temporary directory, containing a single (40 x 20 pixel) PNG image and
+// a `Document` instance with a section, a picture and a text page.
+func newTestDocument(ctx context.Context, t *testing.T) (bucket.Bucket, *Document) {
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to register gocloud buckets, %v", err)
+	}
+
+	b, err := bucket.NewBucket(ctx, fmt.Sprintf("file://%s?metadata=skip", t.TempDir()))
+
+	if err != nil {
+		t.Fatalf("Failed to create bucket, %v", err)
+	}
+
+	wr, err := b.NewWriter(ctx, "example.png", nil)
+
+	if err != nil {
+		t.Fatalf("Failed to create writer, %v", err)
+	}
+
+	err = png.Encode(wr, image.NewGray(image.Rect(0, 0, 40, 20)))
+
+	if err != nil {
+		t.Fatalf("Failed to encode image, %v", err)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close writer, %v", err)
+	}
+
+	doc := &Document{
+		Title:  "Example & Co",
+		Width:  8.5,
+		Height: 11.0,
+		DPI:    150.0,
+		Pages: []*picture.PictureBookPage{
+			{Page: 1, Type: picture.PAGE_TYPE_SECTION, Text: "Part One"},
+			{Page: 2, Type: picture.PAGE_TYPE_PICTURE, Caption: "A <caption>", PixelWidth: 40, PixelHeight: 20, Width: 4.0, Height: 2.0, Bucket: b, Image: "example.png", Format: "png"},
+			{Page: 3, Type: picture.PAGE_TYPE_TEXT, Text: "One.\n\nTwo."},
+		},
+	}
+
+	return b, doc
+}
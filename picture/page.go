@@ -0,0 +1,85 @@
+package picture
+
+import (
+	"github.com/aaronland/go-picturebook/bucket"
+)
+
+// The type of page containing a picture.
+const PAGE_TYPE_PICTURE string = "picture"
+
+// The type of page containing text.
+const PAGE_TYPE_TEXT string = "text"
+
+// The type of page left intentionally blank.
+const PAGE_TYPE_BLANK string = "blank"
+
+// The type of page containing a section divider.
+const PAGE_TYPE_SECTION string = "section"
+
+// type PictureBookPage defines a struct containing details about a page that has been added to a picturebook.
+type PictureBookPage struct {
+	// The page number, starting at 1.
+	Page int `json:"page"`
+	// The type of page. One of PAGE_TYPE_PICTURE, PAGE_TYPE_TEXT, PAGE_TYPE_BLANK or PAGE_TYPE_SECTION.
+	Type string `json:"type"`
+	// The original (relative) path of the image associated with the page.
+	Source string `json:"source,omitempty"`
+	// The (relative) path of the image associated with the page after any processing has been applied.
+	Path string `json:"path,omitempty"`
+	// The caption associated with the page.
+	Caption string `json:"caption,omitempty"`
+	// The text associated with the page.
+	Text string `json:"text,omitempty"`
+	// The width, in pixels, of the image placed on the page.
+	PixelWidth int `json:"pixel_width,omitempty"`
+	// The height, in pixels, of the image placed on the page.
+	PixelHeight int `json:"pixel_height,omitempty"`
+	// The X position, in inches, of the image placed on the page.
+	X float64 `json:"x,omitempty"`
+	// The Y position, in inches, of the image placed on the page.
+	Y float64 `json:"y,omitempty"`
+	// The width, in inches, of the image placed on the page.
+	Width float64 `json:"width,omitempty"`
+	// The height, in inches, of the image placed on the page.
+	Height float64 `json:"height,omitempty"`
+	// The effective resolution, in dots per inch, of the image placed on the page.
+	EffectiveDPI float64 `json:"effective_dpi,omitempty"`
+	// The position and size, in inches, of the border drawn around the image placed on the page.
+	Border *PictureBookBox `json:"border,omitempty"`
+	// The lines of text (a caption, the text of a text page or the title of a section) placed on the page.
+	Lines []*PictureBookTextRun `json:"lines,omitempty"`
+	// The SHA-256 checksum of the original image associated with the page. This is only populated when the `Manifest` option is not empty.
+	Checksum string `json:"checksum,omitempty"`
+	// The `bucket.Bucket` instance where the final image (Image) placed on the page is stored.
+	Bucket bucket.Bucket `json:"-"`
+	// The (relative) path of the final image placed on the page, after any format conversions or rotations have been applied.
+	Image string `json:"-"`
+	// The (short) name of the format of the final image placed on the page, for example "jpeg" or "png".
+	Format string `json:"-"`
+}
+
+// type PictureBookBox defines a struct containing the position and size, in inches, of a box placed on a page.
+type PictureBookBox struct {
+	// The X position, in inches, of the box.
+	X float64 `json:"x"`
+	// The Y position, in inches, of the box.
+	Y float64 `json:"y"`
+	// The width, in inches, of the box.
+	Width float64 `json:"width"`
+	// The height, in inches, of the box.
+	Height float64 `json:"height"`
+}
+
+// type PictureBookTextRun defines a struct containing a single line of text placed on a page.
+type PictureBookTextRun struct {
+	// The text to display.
+	Text string `json:"text"`
+	// The X position, in inches, of the left-hand side of the line.
+	X float64 `json:"x"`
+	// The Y position, in inches, of the top of the line.
+	Y float64 `json:"y"`
+	// The height, in inches, of the line. The text is vertically centered within the line.
+	Height float64 `json:"height"`
+	// The size, in points, of the font used to display the text.
+	Size float64 `json:"size"`
+}
@@ -19,4 +19,6 @@ type PictureBookPicture struct {
 	Bucket bucket.Bucket
 	// The path of any temporary file that has been created in the process of adding an image to a picturebook
 	TempFile string
+	// An optional value used to override the picturebook's `FillPage` option for this image
+	FillPage *bool
 }
@@ -1,26 +1,29 @@
 package picturebook
 
 import (
-	"bytes"
 	"context"
-	"encoding/binary"
+	"errors"
 	"fmt"
-	"image/png"
+	"image"
 	"io"
 	"log/slog"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"codeberg.org/go-pdf/fpdf"
-	"github.com/aaronland/go-image/v2/decode"
 	"github.com/aaronland/go-image/v2/rotate"
 	"github.com/aaronland/go-picturebook/bucket"
 	"github.com/aaronland/go-picturebook/caption"
 	"github.com/aaronland/go-picturebook/filter"
+	"github.com/aaronland/go-picturebook/layout"
+	"github.com/aaronland/go-picturebook/output"
 	"github.com/aaronland/go-picturebook/picture"
+	"github.com/aaronland/go-picturebook/preview"
 	"github.com/aaronland/go-picturebook/process"
 	"github.com/aaronland/go-picturebook/progress"
+	"github.com/aaronland/go-picturebook/render"
 	"github.com/aaronland/go-picturebook/sort"
 	"github.com/aaronland/go-picturebook/tempfile"
 	"github.com/aaronland/go-picturebook/text"
@@ -31,64 +34,107 @@ import (
 // MM2INCH defines the number if millimeters in an inch.
 const MM2INCH float64 = 25.4
 
+// ocra_font is the OCRA font, loaded once and shared by every picturebook which uses it, and ocra_font_err the error, if any, loading it.
+var ocra_font *ocra.FPDFFont
+var ocra_font_err error
+var ocra_font_once sync.Once
+
+// loadOCRAFont returns the OCRA font, loading it the first time it is called.
+func loadOCRAFont() (*ocra.FPDFFont, error) {
+
+	ocra_font_once.Do(func() {
+		ocra_font, ocra_font_err = ocra.LoadFPDFFont()
+	})
+
+	return ocra_font, ocra_font_err
+}
+
 // PictureBookOptions defines a struct containing configuration information for a given picturebook instance.
 type PictureBookOptions struct {
 	// The orientation of the final picturebook. Valid options are "P" and "L" for portrait and landscape respectively.
-	Orientation string
+	Orientation string `json:"orientation"`
 	// A string label corresponding to known size. Valid options are "a1", "a2", "a3", "a4", "a5", "a6", "a7", "letter", "legal" and "tabloid".
-	Size string
+	Size string `json:"size"`
 	// The width of the final picturebook.
-	Width float64
+	Width float64 `json:"width"`
 	// The height of the final picturebook.
-	Height float64
+	Height float64 `json:"height"`
 	// The unit of measurement to use for the `Width` and `Height` options.
-	Units string
+	Units string `json:"units"`
 	// The number dots per inch to use when calculating the size of the final picturebook. Valid options are "inches", "centimeters", "millimeters".
-	DPI float64
+	DPI float64 `json:"dpi"`
 	// The size of any border to apply to each image in the final picturebook.
-	Border float64
+	Border float64 `json:"border"`
 	// The size of any additional bleed to apply to the final picturebook.
-	Bleed float64
+	Bleed float64 `json:"bleed"`
 	// The size of any margin to add to the top of each page.
-	MarginTop float64
+	MarginTop float64 `json:"margin_top"`
 	// The size of any margin to add to the bottom of each page.
-	MarginBottom float64
+	MarginBottom float64 `json:"margin_bottom"`
 	// The size of any margin to add to the left-hand side of each page.
-	MarginLeft float64
+	MarginLeft float64 `json:"margin_left"`
 	// The size of any margin to add to the right-hand side of each page.
-	MarginRight float64
+	MarginRight float64 `json:"margin_right"`
 	// An optional `filter.Filter` instance used to determine whether or not an image should be included in the final picturebook.
-	Filter filter.Filter
+	Filter filter.Filter `json:"-"`
 	// Zero or more optional `process.Process` instance used to transform images being included in the final picturebook.
-	PreProcess process.Process
+	PreProcess process.Process `json:"-"`
 	// Zero or more optional `process.Process` instance used to transform images after having been rotated to fill the page and before being included in the final picturebook.
-	RotateToFillPostProcess process.Process
+	RotateToFillPostProcess process.Process `json:"-"`
 	// An optional `caption.Caption` instance used to derive a caption string for each image added to the final picturebook.
-	Caption caption.Caption
+	Caption caption.Caption `json:"-"`
 	// An optional `text.Text` instance used to derive a text string for each image added to the final picturebook.
-	Text text.Text
+	Text text.Text `json:"-"`
 	// An optional `sort.Sorter` instance used to sort images before they are added to the final picturebook.
-	Sort sort.Sorter
+	Sort sort.Sorter `json:"-"`
 	// A boolean value signaling that an image should be rotated if necessary to fill the maximum amount of any given page.
-	FillPage bool
+	FillPage bool `json:"fill_page"`
 	// A boolean value to enable verbose logging during the creation of a picturebook.
-	Verbose bool
+	Verbose bool `json:"verbose"`
 	// A boolean value to enable to use of an OCRA font for writing captions.
-	OCRAFont bool
+	OCRAFont bool `json:"ocra_font"`
 	// A `aaronland/go-picturebook/bucket.Bucket` instance where picturebook data is read from.
-	Source bucket.Bucket
+	Source bucket.Bucket `json:"-"`
 	// A `aaronland/go-picturebook/bucket.Bucket` instance where the final picturebook is written to.
-	Target bucket.Bucket
+	Target bucket.Bucket `json:"-"`
 	// A `aaronland/go-picturebook/bucket.Bucket` instance where the temporary files necessary in the creation of the picturebook are written to.
-	Temporary bucket.Bucket
+	Temporary bucket.Bucket `json:"-"`
 	// A `aaronland/go-picturebook/progress.Monitor` instance used to signal picturebook creation progress.
-	Monitor progress.Monitor
+	Monitor progress.Monitor `json:"-"`
 	// A boolean value signaling that images should only be added on even-numbered pages.
-	EvenOnly bool
+	EvenOnly bool `json:"even_only"`
 	// A boolean value signaling that images should only be added on odd-numbered pages.
-	OddOnly bool
+	OddOnly bool `json:"odd_only"`
 	// An optional value to indicate that a picturebook should not exceed this number of pages
-	MaxPages int
+	MaxPages int `json:"max_pages"`
+	// The maximum number of images to gather and pre-process concurrently. Values less than 1 are treated as 1.
+	Workers int `json:"workers"`
+	// A boolean value signaling that all the steps to create a picturebook should be taken but without rendering
+	// any pages. If true the `Save` method will write a manifest of the pages that would have been added, encoded
+	// as JSON unless the `Manifest` option is "csv", rather than a picturebook document.
+	Debug bool `json:"debug"`
+	// The format of an optional manifest file, describing each page in the picturebook, to write alongside the picturebook
+	// when it is saved. Valid options are "json" and "csv". If empty no manifest file is written.
+	Manifest string `json:"manifest,omitempty"`
+	// The title of the picturebook. This is used by outputs, like EPUB, which record a title in the document itself.
+	Title string `json:"title,omitempty"`
+	// An optional `output.Output` instance used to write the picturebook in a format other than PDF. If nil the picturebook is written as a PDF document.
+	Output output.Output `json:"-"`
+	// An optional `aaronland/go-picturebook/bucket.Bucket` instance where PNG previews of each page, and a contact sheet of all the pages, are written when the picturebook is saved.
+	Preview bucket.Bucket `json:"-"`
+	// The number of dots per inch at which page previews are rendered. If 0 then `preview.DEFAULT_DPI` is used.
+	PreviewDPI float64 `json:"preview_dpi,omitempty"`
+	// Optional settings for checking each image added to the picturebook for problems, like low resolution, which
+	// are not apparent on screen but are when printed. If nil images are not checked.
+	Preflight *PreflightOptions `json:"preflight,omitempty"`
+	// The policy for images which can not be added to the picturebook when using the `AddPictures` or `AddPicturePage` methods. Valid options are
+	// "skip" (`ERROR_POLICY_SKIP`), to skip and record those images, and "strict" (`ERROR_POLICY_STRICT`), to stop creating the
+	// picturebook and return an error. If empty then "skip" is used.
+	ErrorPolicy string `json:"error_policy,omitempty"`
+	// An optional `aaronland/go-picturebook/bucket.Bucket` instance where the results of processes created after the picturebook,
+	// like those defined by the pages of a spec file, are cached. It is not applied to the `PreProcess` option which, if it should be
+	// cached, is expected to have been created with its own cache (see `process.MultiProcessOptions`).
+	Cache bucket.Bucket `json:"-"`
 }
 
 // type PictureBookMargins defines a struct for storing margins to be applied to a picturebook
@@ -153,12 +199,28 @@ type PictureBook struct {
 	Text PictureBookText
 	// The `PictureBookOptions` used to create this picturebook
 	Options *PictureBookOptions
+	// The `layout.Layout` instance used to calculate the position of images and text on each page
+	Layout *layout.Layout
+	// The `render.Renderer` instance used to draw each page
+	Renderer render.Renderer
 	// The `GatherPicturesProcessFunc` function used to determine whether an image is included in a picturebook
 	ProcessFunc GatherPicturesProcessFunc
 	// The number of pages in this picturebook
 	pages int
 	// A list of temporary files used in the creation of a picturebook and to be removed when the picturebook is saved
 	tmpfiles []string
+	// A list of `picture.PictureBookPage` instances describing each page added to the picturebook
+	manifest []*picture.PictureBookPage
+	// An in-memory `bucket.Bucket` instance used to store images added using the `AddImage` and `AddReader` methods
+	memory bucket.Bucket
+	// A list of `PreflightWarning` instances for problems found in the images added to the picturebook
+	preflight []*PreflightWarning
+	// A list of `SkippedPicture` instances for files which were not added to the picturebook
+	skipped []*SkippedPicture
+	// A `BuildSummary` instance used to accumulate counts and timings as the picturebook is created
+	summary *BuildSummary
+	// The time the picturebook was created
+	created time.Time
 
 	monitor progress.Monitor
 }
@@ -169,6 +231,7 @@ type GatherPicturesProcessFunc func(context.Context, string) (*picture.PictureBo
 //	DefaultGatherPicturesProcessFunc returns a default GatherPicturesProcessFunc used to derive a `picture.PictureBookPicture` instance
 //
 // from the path to an image file. It applies any filters and transformation processes and derives caption data per settings defined in 'pb_opts'.
+// Images which are excluded by a filter return a `FilteredError` error. Files which are not images return `ErrNotImage`.
 func DefaultGatherPicturesProcessFunc(pb_opts *PictureBookOptions) (GatherPicturesProcessFunc, error) {
 
 	fn := func(ctx context.Context, path string) (*picture.PictureBookPicture, error) {
@@ -202,21 +265,37 @@ func DefaultGatherPicturesProcessFunc(pb_opts *PictureBookOptions) (GatherPictur
 		}
 
 		if !strings.HasPrefix(mtype.String(), "image/") {
-			logger.Debug("File does not appear to be an image, skipping", "mime", mtype.String())
-			return nil, nil
+			return nil, fmt.Errorf("%w (%s)", ErrNotImage, mtype.String())
+		}
+
+		err = ensureSupportedFormat(mtype.String())
+
+		if err != nil {
+			return nil, err
 		}
 
 		if pb_opts.Filter != nil {
 
-			ok, err := pb_opts.Filter.Continue(ctx, pb_opts.Source, abs_path)
+			var ok bool
+			var filter_uri string
+
+			if mf, is_multi := pb_opts.Filter.(*filter.MultiFilter); is_multi {
+				ok, filter_uri, err = mf.ContinueWithURI(ctx, pb_opts.Source, abs_path)
+			} else {
+				ok, err = pb_opts.Filter.Continue(ctx, pb_opts.Source, abs_path)
+			}
 
 			if err != nil {
-				logger.Error("Failed to filter image", "error", err)
-				return nil, nil
+				return nil, fmt.Errorf("Failed to filter image, %w", err)
 			}
 
 			if !ok {
-				return nil, nil
+
+				if filter_uri == "" {
+					filter_uri = fmt.Sprintf("%T", pb_opts.Filter)
+				}
+
+				return nil, &FilteredError{Filter: filter_uri}
 			}
 
 			logger.Debug("Include image")
@@ -230,8 +309,7 @@ func DefaultGatherPicturesProcessFunc(pb_opts *PictureBookOptions) (GatherPictur
 			txt, err := pb_opts.Caption.Text(ctx, pb_opts.Source, abs_path)
 
 			if err != nil {
-				logger.Error("Failed to derive caption text", "error", err)
-				return nil, nil
+				return nil, fmt.Errorf("Failed to derive caption text, %w", err)
 			}
 
 			caption = txt
@@ -242,8 +320,7 @@ func DefaultGatherPicturesProcessFunc(pb_opts *PictureBookOptions) (GatherPictur
 			txt, err := pb_opts.Text.Body(ctx, pb_opts.Source, abs_path)
 
 			if err != nil {
-				logger.Error("Failed to derive text body", "error", err)
-				return nil, nil
+				return nil, fmt.Errorf("Failed to derive text body, %w", err)
 			}
 
 			text_body = txt
@@ -261,8 +338,7 @@ func DefaultGatherPicturesProcessFunc(pb_opts *PictureBookOptions) (GatherPictur
 			processed_path, err := pb_opts.PreProcess.Transform(ctx, pb_opts.Source, pb_opts.Temporary, abs_path)
 
 			if err != nil {
-				logger.Error("Failed to process image", "error", err)
-				return nil, nil
+				return nil, fmt.Errorf("Failed to process image, %w", err)
 			}
 
 			logger.Debug("After pre-processing path becomes", "processed_path", processed_path)
@@ -308,6 +384,7 @@ func NewPictureBookDefaultOptions(ctx context.Context) (*PictureBookOptions, err
 		MarginLeft:   1.0,
 		MarginRight:  1.0,
 		Verbose:      false,
+		Workers:      1,
 	}
 
 	return opts, nil
@@ -375,6 +452,24 @@ func NewPictureBook(ctx context.Context, opts *PictureBookOptions) (*PictureBook
 		default:
 			return nil, fmt.Errorf("Invalid or unsupported unit '%s'", opts.Units)
 		}
+
+		opts.Units = "inches"
+	}
+
+	switch opts.Manifest {
+	case "", MANIFEST_FORMAT_JSON, MANIFEST_FORMAT_CSV:
+		// pass
+	default:
+		return nil, fmt.Errorf("Invalid or unsupported manifest format '%s'", opts.Manifest)
+	}
+
+	switch opts.ErrorPolicy {
+	case "":
+		opts.ErrorPolicy = ERROR_POLICY_SKIP
+	case ERROR_POLICY_SKIP, ERROR_POLICY_STRICT:
+		// pass
+	default:
+		return nil, fmt.Errorf("Invalid or unsupported error policy '%s'", opts.ErrorPolicy)
 	}
 
 	// log.Printf("%0.2f x %0.2f (%s)\n", opts.Width, opts.Height, opts.Size)
@@ -404,7 +499,7 @@ func NewPictureBook(ctx context.Context, opts *PictureBookOptions) (*PictureBook
 
 	if opts.OCRAFont {
 
-		font, err := ocra.LoadFPDFFont()
+		font, err := loadOCRAFont()
 
 		if err != nil {
 			return nil, fmt.Errorf("Failed to load OCRA font, %w", err)
@@ -467,6 +562,36 @@ func NewPictureBook(ctx context.Context, opts *PictureBookOptions) (*PictureBook
 		Height: canvas_h,
 	}
 
+	renderer := render.NewFPDFRenderer(pdf, opts.DPI)
+
+	font_sz, _ := pdf.GetFontSize()
+	pdf_w, pdf_h := pdf.GetPageSize()
+
+	l := &layout.Layout{
+		DPI:         opts.DPI,
+		Orientation: opts.Orientation,
+		PageWidth:   pdf_w,
+		PageHeight:  pdf_h,
+		Margins: layout.Insets{
+			Top:    margin_top,
+			Bottom: margin_bottom,
+			Left:   margin_left,
+			Right:  margin_right,
+		},
+		Borders: layout.Insets{
+			Top:    border_top,
+			Bottom: border_bottom,
+			Left:   border_left,
+			Right:  border_right,
+		},
+		CanvasWidth:  canvas_w,
+		CanvasHeight: canvas_h,
+		FontSize:     font_sz,
+		TextMargin:   t.Margin,
+		CellMargin:   pdf.GetCellMargin(),
+		Measurer:     renderer,
+	}
+
 	tmpfiles := make([]string, 0)
 	mu := new(sync.Mutex)
 
@@ -484,15 +609,22 @@ func NewPictureBook(ctx context.Context, opts *PictureBookOptions) (*PictureBook
 		Canvas:      canvas,
 		Text:        t,
 		Options:     opts,
+		Layout:      l,
+		Renderer:    renderer,
 		ProcessFunc: process_func,
 		pages:       0,
 		tmpfiles:    tmpfiles,
+		manifest:    make([]*picture.PictureBookPage, 0),
+		summary:     newBuildSummary(),
+		created:     time.Now(),
 	}
 
 	return &pb, nil
 }
 
-// AddPictures adds images founds in one or more folders defined 'paths' to the picturebook instance.
+// AddPictures adds images founds in one or more folders defined 'paths' to the picturebook instance. Images which can not be
+// gathered or added are handled according to the `ErrorPolicy` option: if it is "skip" they are recorded, and available using the
+// `SkippedPictures` method, and if it is "strict" an error is returned as soon as the first one is encountered.
 func (pb *PictureBook) AddPictures(ctx context.Context, paths []string) error {
 
 	pictures, err := pb.GatherPictures(ctx, paths)
@@ -503,8 +635,16 @@ func (pb *PictureBook) AddPictures(ctx context.Context, paths []string) error {
 
 	slog.Debug("Pictures gathered", "count", len(pictures))
 
+	d := progress.NewDispatcher(ctx, pb.Options.Monitor)
+	defer d.Close()
+
 	if pb.Options.Sort != nil {
 
+		t1 := time.Now()
+
+		sort_tracker := progress.NewTracker(progress.STAGE_SORT, 1)
+		d.Signal(ctx, sort_tracker.Event(""))
+
 		sorted, err := pb.Options.Sort.Sort(ctx, pb.Options.Source, pictures)
 
 		if err != nil {
@@ -512,228 +652,490 @@ func (pb *PictureBook) AddPictures(ctx context.Context, paths []string) error {
 		}
 
 		pictures = sorted
+
+		d.Signal(ctx, sort_tracker.Next(""))
+
+		pb.Mutex.Lock()
+		pb.timeStage(progress.STAGE_SORT, t1)
+		pb.Mutex.Unlock()
 	}
 
+	render_tracker := progress.NewTracker(progress.STAGE_RENDER, len(pictures))
+	d.Signal(ctx, render_tracker.Event(""))
+
 	for _, pic := range pictures {
 
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		pb.Mutex.Lock()
 		pb.pages += 1
 		pagenum := pb.pages
 		pb.Mutex.Unlock()
 
-		go func(page_num int) {
-			page_count := max(pb.pages, len(pictures))
-			ev := progress.NewEvent(page_num, page_count)
-			pb.Options.Monitor.Signal(ctx, ev)
-		}(pb.pages)
+		err := pb.addPictureAndText(ctx, pagenum, pic)
 
-		var err error
+		if err != nil && pb.strict() {
+			return fmt.Errorf("Failed to add picture %s, %w", pic.Source, err)
+		}
+
+		if err != nil {
+			pb.Mutex.Lock()
+			pb.skipPicture(pic.Source, err)
+			pb.Mutex.Unlock()
+		}
 
-		if pb.Options.EvenOnly {
+		d.Signal(ctx, render_tracker.Next(pic.Source))
+	}
 
-			if pagenum%2 != 0 {
-				pb.AddBlankPage(ctx, pagenum)
-				pb.pages += 1
-				pagenum = pb.pages
-			}
+	d.Clear()
+	return nil
+}
 
-			if pic.Text != "" {
-				pb.AddText(ctx, pagenum, pic)
-				pb.pages += 1
-				pagenum = pb.pages
+// AddPicturePage derives a picture from 'path' using 'fn', or the `ProcessFunc` function if nil, and adds it, and its text if present,
+// as the next page in the picturebook. It is used by code, like the `spec` package, which adds pictures one at a time rather than
+// gathering them with `AddPictures`, and counts the picture in the picturebook's `BuildSummary` the same way. Pictures excluded by a
+// filter are not added and pictures which can not be derived or added are handled according to the `ErrorPolicy` option.
+func (pb *PictureBook) AddPicturePage(ctx context.Context, path string, fn GatherPicturesProcessFunc) error {
 
-				pb.AddBlankPage(ctx, pagenum)
-				pb.pages += 1
-				pagenum = pb.pages
-			}
+	if fn == nil {
+		fn = pb.ProcessFunc
+	}
+
+	t1 := time.Now()
+
+	pic, err := fn(ctx, path)
+
+	pb.Mutex.Lock()
+	pb.summary.Files += 1
+	pb.timeStage(progress.STAGE_PROCESS, t1)
+	pb.Mutex.Unlock()
+
+	var filtered_err *FilteredError
+
+	if errors.As(err, &filtered_err) {
+
+		pb.Mutex.Lock()
+		pb.summary.Filtered[filtered_err.Filter] += 1
+		pb.Mutex.Unlock()
+
+		return nil
+	}
+
+	if err == nil && pic == nil {
+		return nil
+	}
+
+	if err == nil {
+
+		pb.Mutex.Lock()
+		pb.countPicture(pic)
+
+		// Pages may have been added without updating the page count (for example using AddText or AddSection) so
+		// the page number is derived from the pages which have actually been added
+
+		pb.pages = len(pb.manifest) + 1
+		pagenum := pb.pages
+		pb.Mutex.Unlock()
+
+		err = pb.addPictureAndText(ctx, pagenum, pic)
+	}
+
+	// Files which are not images are always skipped, regardless of the error policy, the same way they are by GatherPictures
+
+	if err != nil && pb.strict() && !errors.Is(err, ErrNotImage) {
+		return fmt.Errorf("Failed to add picture %s, %w", path, err)
+	}
+
+	if err != nil {
+		pb.Mutex.Lock()
+		pb.skipPicture(path, err)
+		pb.Mutex.Unlock()
+	}
+
+	return nil
+}
+
+// addPictureAndText adds 'pic', and its text if present, to the picturebook starting at page 'pagenum'. Blank pages are
+// added as necessary to honour the `EvenOnly` and `OddOnly` options. If 'pic' can not be added the page count is rewound
+// so that the next picture is added to the page this one would have been added to (or the page after any pages which
+// were added before the error).
+func (pb *PictureBook) addPictureAndText(ctx context.Context, pagenum int, pic *picture.PictureBookPicture) (err error) {
+
+	pb.Mutex.Lock()
+	first_page := pagenum
+	manifest_count := len(pb.manifest)
+	pb.Mutex.Unlock()
+
+	defer func() {
+
+		if err != nil {
+			pb.Mutex.Lock()
+			pb.pages = first_page - 1 + (len(pb.manifest) - manifest_count)
+			pb.Mutex.Unlock()
+		}
+	}()
+
+	// addPage calls 'fn' to add a page at 'pagenum' and then advances 'pagenum' to the next page
+
+	addPage := func(fn func() error) error {
+
+		err := fn()
+
+		if err != nil {
+			return err
+		}
+
+		pb.pages += 1
+		pagenum = pb.pages
+		return nil
+	}
+
+	addBlank := func() error {
+		return pb.AddBlankPage(ctx, pagenum)
+	}
+
+	addText := func() error {
+		return pb.AddText(ctx, pagenum, pic)
+	}
 
-			err = pb.AddPicture(ctx, pagenum, pic)
+	if pb.Options.EvenOnly {
 
-		} else if pb.Options.OddOnly {
+		if pagenum%2 != 0 {
 
-			if pagenum == 1 {
-				pb.AddBlankPage(ctx, pagenum)
-				pb.pages += 1
-				pagenum = pb.pages
+			err := addPage(addBlank)
+
+			if err != nil {
+				return fmt.Errorf("Failed to add blank page, %w", err)
 			}
+		}
 
-			if pagenum%2 == 0 {
-				err = pb.AddBlankPage(ctx, pagenum)
-				pb.pages += 1
-				pagenum = pb.pages
+		if pic.Text != "" {
+
+			err := addPage(addText)
+
+			if err != nil {
+				return fmt.Errorf("Failed to add text, %w", err)
 			}
 
-			if pic.Text != "" {
-				pb.AddText(ctx, pagenum, pic)
-				pb.pages += 1
-				pagenum = pb.pages
+			err = addPage(addBlank)
 
-				pb.AddBlankPage(ctx, pagenum)
-				pb.pages += 1
-				pagenum = pb.pages
+			if err != nil {
+				return fmt.Errorf("Failed to add blank page, %w", err)
 			}
+		}
 
-			err = pb.AddPicture(ctx, pagenum, pic)
+	} else if pb.Options.OddOnly {
 
-		} else {
+		if pagenum == 1 {
 
-			if pic.Text != "" {
-				pb.AddText(ctx, pagenum, pic)
-				pb.pages += 1
-				pagenum = pb.pages
+			err := addPage(addBlank)
+
+			if err != nil {
+				return fmt.Errorf("Failed to add blank page, %w", err)
 			}
+		}
+
+		if pagenum%2 == 0 {
+
+			err := addPage(addBlank)
 
-			err = pb.AddPicture(ctx, pagenum, pic)
+			if err != nil {
+				return fmt.Errorf("Failed to add blank page, %w", err)
+			}
 		}
 
-		if err != nil {
-			slog.Error("Failed to add picture", "path", pic.Path, "error", err)
-			return err
+		if pic.Text != "" {
+
+			err := addPage(addText)
+
+			if err != nil {
+				return fmt.Errorf("Failed to add text, %w", err)
+			}
+
+			err = addPage(addBlank)
+
+			if err != nil {
+				return fmt.Errorf("Failed to add blank page, %w", err)
+			}
 		}
-	}
 
-	err = pb.Options.Monitor.Clear()
+	} else {
 
-	if err != nil {
-		slog.Warn("Failed to clear progress monitor", "error", err)
+		if pic.Text != "" {
+
+			err := addPage(addText)
+
+			if err != nil {
+				return fmt.Errorf("Failed to add text, %w", err)
+			}
+		}
 	}
 
-	return nil
+	return pb.AddPicture(ctx, pagenum, pic)
 }
 
 // GatherPictures collects all the images in one or more folders defined by 'paths' and returns a list of `picture.PictureBookPicture` instances.
+// Each path is processed by the `ProcessFunc` function using up to `Options.Workers` concurrent workers. The order of the pictures returned
+// is the same as the order in which their paths were gathered, regardless of the number of workers. Paths which can not be gathered or processed
+// are handled according to the `ErrorPolicy` option. Files which are not images are always skipped.
 func (pb *PictureBook) GatherPictures(ctx context.Context, paths []string) ([]*picture.PictureBookPicture, error) {
 
-	pictures := make([]*picture.PictureBookPicture, 0)
-	var err error
+	t1 := time.Now()
 
-	i := 0
+	// Gathering is cancelled if 'ctx' is done or if the error policy is "strict" and a path can not be gathered or processed
 
-	for path, p_err := range pb.Options.Source.GatherPictures(ctx, paths...) {
+	parent_ctx := ctx
 
-		if err != nil {
-			slog.Error("Failed to gather pictures", "error", err)
-			err = p_err
-			break
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	d := progress.NewDispatcher(ctx, pb.Options.Monitor)
+	defer d.Close()
+
+	gather_tracker := progress.NewTracker(progress.STAGE_GATHER, progress.UNKNOWN_COUNT)
+	process_tracker := progress.NewTracker(progress.STAGE_PROCESS, progress.UNKNOWN_COUNT)
+
+	workers := max(pb.Options.Workers, 1)
+
+	// A map of the order a path was gathered in to its corresponding picture
+	results := make(map[int]*picture.PictureBookPicture)
+
+	// A map of the order a path was gathered in to the reason it was skipped
+	skipped := make(map[int]*SkippedPicture)
+
+	// The first error encountered if the error policy is "strict"
+	var gather_err error
+
+	// The number of images excluded by each filter
+	filtered := make(map[string]int)
+
+	// The (wall) time between the start of the first call to ProcessFunc and the end of the last one
+	var process_start time.Time
+	var process_end time.Time
+
+	mu := new(sync.Mutex)
+	wg := new(sync.WaitGroup)
+
+	throttle := make(chan bool, workers)
+
+	// skip records that the path gathered at 'idx' was skipped, or cancels gathering if the error policy is "strict"
+
+	skip := func(idx int, path string, err error) {
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Errors caused by gathering being cancelled are not skipped pictures
+
+		if ctx.Err() != nil {
+			return
 		}
 
-		logger := slog.Default()
-		logger = logger.With("path", path)
+		if pb.strict() && !errors.Is(err, ErrNotImage) {
 
-		i += 1
+			if gather_err == nil {
+				gather_err = fmt.Errorf("Failed to gather %s, %w", path, err)
+				cancel()
+			}
 
-		ev := progress.NewEvent(i, -1)
-		ev.Message = "Gathering items"
+			return
+		}
 
-		pb.Options.Monitor.Signal(ctx, ev)
+		skipped[idx] = newSkippedPicture(path, err)
+	}
 
-		logger.Debug("Process image")
+	i := 0
 
-		pic, pic_err := pb.ProcessFunc(ctx, path)
+	for path, err := range pb.Options.Source.GatherPictures(ctx, paths...) {
 
-		if err != nil {
-			logger.Error("Failed to process path", "error", err)
-			err = pic_err
+		if ctx.Err() != nil {
 			break
 		}
 
-		if pic == nil {
-			logger.Debug("No picture, skipping")
+		idx := i
+		i += 1
+
+		if err != nil {
+			skip(idx, path, err)
 			continue
 		}
 
-		logger.Debug("Append picture", "source", pic.Source, "picture", pic.Path)
-		pictures = append(pictures, pic)
-	}
+		ev := gather_tracker.Next(path)
+		ev.Message = "Gathering items"
 
-	err = pb.Options.Monitor.Clear()
+		d.Signal(ctx, ev)
 
-	if err != nil {
-		slog.Warn("Failed to clear monitor", "error", err)
+		throttle <- true
+		wg.Add(1)
+
+		go func(idx int, path string) {
+
+			defer func() {
+				<-throttle
+				wg.Done()
+			}()
+
+			logger := slog.Default()
+			logger = logger.With("path", path)
+
+			logger.Debug("Process image")
+
+			mu.Lock()
+
+			if process_start.IsZero() {
+				process_start = time.Now()
+			}
+
+			mu.Unlock()
+
+			pic, err := pb.ProcessFunc(ctx, path)
+
+			mu.Lock()
+			process_end = time.Now()
+			mu.Unlock()
+
+			d.Signal(ctx, process_tracker.Next(path))
+
+			var filtered_err *FilteredError
+
+			if errors.As(err, &filtered_err) {
+
+				logger.Debug("Image excluded by filter", "filter", filtered_err.Filter)
+
+				mu.Lock()
+				filtered[filtered_err.Filter] += 1
+				mu.Unlock()
+
+				return
+			}
+
+			if err != nil {
+				skip(idx, path, err)
+				return
+			}
+
+			if pic == nil {
+				logger.Debug("No picture, skipping")
+				return
+			}
+
+			logger.Debug("Append picture", "source", pic.Source, "picture", pic.Path)
+
+			mu.Lock()
+			results[idx] = pic
+			mu.Unlock()
+
+		}(idx, path)
 	}
 
-	return pictures, err
-}
+	wg.Wait()
 
-// AddBlankPage add a blank page the final PDF document at page 'pagenum'.
-func (pb *PictureBook) AddBlankPage(ctx context.Context, pagenum int) error {
-	pb.PDF.AddPage()
-	return nil
-}
+	d.Clear()
 
-// AddText add the value of `pic.Text` on the adjacent page to `pic`.
-func (pb *PictureBook) AddText(ctx context.Context, pagenum int, pic *picture.PictureBookPicture) error {
+	if gather_err != nil {
+		return nil, gather_err
+	}
+
+	if parent_ctx.Err() != nil {
+		return nil, parent_ctx.Err()
+	}
+
+	pictures := make([]*picture.PictureBookPicture, 0, len(results))
 
 	pb.Mutex.Lock()
 	defer pb.Mutex.Unlock()
 
-	pb.PDF.AddPage()
+	pb.summary.Files += i
 
-	_, line_h := pb.PDF.GetFontSize()
+	if !process_start.IsZero() {
+		pb.summary.Timings[progress.STAGE_PROCESS] += process_end.Sub(process_start)
+	}
 
-	max_w := pb.Canvas.Width
-	// max_h := pb.Canvas.Height - (pb.Text.Margin + line_h)
+	for uri, count := range filtered {
+		pb.summary.Filtered[uri] += count
+	}
 
-	/*
-		w := max_w
-		h := max_h
-	*/
+	for idx := 0; idx < i; idx++ {
 
-	margins := pb.Margins
+		pic, ok := results[idx]
 
-	current_x := margins.Left
-	current_y := margins.Top
+		if ok {
+			pictures = append(pictures, pic)
+			pb.countPicture(pic)
+		}
 
-	// START OF reconcile me with code for rendering captions...
+		s, ok := skipped[idx]
 
-	prepped := text.PrepareText(pb.PDF, pb.Options.DPI, max_w, pic.Text)
+		if ok {
+			pb.skipped = append(pb.skipped, s)
+		}
+	}
 
-	for _, txt := range prepped {
+	pb.timeStage(progress.STAGE_GATHER, t1)
 
-		txt = strings.TrimSpace(txt)
+	return pictures, nil
+}
 
-		// txt_w := pb.PDF.GetStringWidth(txt)
-		txt_h := line_h
+// AddBlankPage add a blank page the final PDF document at page 'pagenum'.
+func (pb *PictureBook) AddBlankPage(ctx context.Context, pagenum int) error {
 
-		/*
-			txt_w = txt_w + pb.Text.Margin
-		*/
-		txt_h = txt_h + pb.Text.Margin
+	pb.Mutex.Lock()
+	defer pb.Mutex.Unlock()
 
-		// log.Printf("DEBUG %d max: %f03 w: %f03 %s\n", len(txt), max_w, txt_w*pb.Options.DPI, txt)
-		// please do this in the constructor...
-		// (20171128/thisisaaronland)
+	defer pb.timeStage(progress.STAGE_RENDER, time.Now())
 
-		font_sz, _ := pb.PDF.GetFontSize()
-		pb.PDF.SetFontSize(font_sz + 2)
+	page := &picture.PictureBookPage{
+		Type: picture.PAGE_TYPE_BLANK,
+	}
 
-		_, line_h := pb.PDF.GetFontSize()
+	return pb.addPage(ctx, page)
+}
 
-		pb.PDF.SetFontSize(font_sz)
+// AddText add the value of `pic.Text` on the adjacent page to `pic`.
+func (pb *PictureBook) AddText(ctx context.Context, pagenum int, pic *picture.PictureBookPicture) error {
 
-		txt_x := current_x / pb.Options.DPI
-		txt_y := (current_y / pb.Options.DPI)
+	pb.Mutex.Lock()
+	defer pb.Mutex.Unlock()
 
-		// slog.Debug("[%d][%s] text at %0.2f x %0.2f (- x %0.2f)\n", pagenum, pic.Path, txt_x, txt_y, txt_h)
+	defer pb.timeStage(progress.STAGE_RENDER, time.Now())
 
-		pb.PDF.SetXY(txt_x, txt_y)
+	page := &picture.PictureBookPage{
+		Type:   picture.PAGE_TYPE_TEXT,
+		Source: pic.Source,
+		Text:   pic.Text,
+		Lines:  pb.Layout.Text(pic.Text),
+	}
 
-		html := pb.PDF.HTMLBasicNew()
-		html.Write(line_h, txt)
+	return pb.addPage(ctx, page)
+}
 
-		current_y += ((txt_h * pb.Options.DPI) * .65)
-	}
+// AddSection adds a section divider page, displaying 'title' centered on the page, to the final PDF document at page 'pagenum'.
+func (pb *PictureBook) AddSection(ctx context.Context, pagenum int, title string) error {
 
-	// END OF reconcile me with code for rendering captions...
+	pb.Mutex.Lock()
+	defer pb.Mutex.Unlock()
 
-	return nil
+	defer pb.timeStage(progress.STAGE_RENDER, time.Now())
+
+	page := &picture.PictureBookPage{
+		Type:  picture.PAGE_TYPE_SECTION,
+		Text:  title,
+		Lines: pb.Layout.Section(title),
+	}
+
+	return pb.addPage(ctx, page)
 }
 
+// AddPicture adds 'pic' to the final PDF document at page 'pagenum'.
 func (pb *PictureBook) AddPicture(ctx context.Context, pagenum int, pic *picture.PictureBookPicture) error {
 
 	pb.Mutex.Lock()
 	defer pb.Mutex.Unlock()
 
+	defer pb.timeStage(progress.STAGE_RENDER, time.Now())
+
 	logger := slog.Default()
 	logger = logger.With("path", pic.Path)
 	logger = logger.With("pagenum", pagenum)
@@ -757,53 +1159,87 @@ func (pb *PictureBook) AddPicture(ctx context.Context, pagenum int, pic *picture
 
 	defer im_r.Close()
 
-	decode_opts := &decode.DecodeImageOptions{
-		Rotate: false,
-	}
+	// Start by reading only the image header to determine its dimensions and format. The
+	// image itself is only decoded (below) if it needs to be converted or rotated. Otherwise
+	// fpdf will read the original bytes when the image is registered.
 
-	im, im_format, _, err := decode.DecodeImageWithOptions(ctx, im_r, decode_opts)
+	var im image.Image
+	var format string
+
+	var im_w int
+	var im_h int
+
+	im_cfg, err := decodeImageConfig(ctx, im_r)
 
 	if err != nil {
-		logger.Error("Failed to decode image", "error", err)
-		return nil
+
+		// For example, HEIC images which are not supported by image.DecodeConfig
+
+		logger.Debug("Failed to decode image config, decoding image instead", "error", err)
+
+		im, format, err = decodeImage(ctx, im_r)
+
+		if err != nil {
+			return fmt.Errorf("Failed to decode image %s, %w", abs_path, err)
+		}
+
+		dims := im.Bounds()
+
+		im_w = dims.Max.X
+		im_h = dims.Max.Y
+
+	} else {
+
+		format = im_cfg.Format
+		im_w = im_cfg.Width
+		im_h = im_cfg.Height
 	}
 
-	if im == nil {
-		logger.Error("Image decoded but did not return an image object, skipping.")
+	// ensureImage decodes the image if it has not already been decoded.
+
+	ensureImage := func() error {
+
+		if im != nil {
+			return nil
+		}
+
+		new_im, _, err := decodeImage(ctx, im_r)
+
+		if err != nil {
+			return err
+		}
+
+		im = new_im
 		return nil
 	}
 
-	format := strings.Replace(im_format, "image/", "", 1)
+	format = strings.Replace(format, "image/", "", 1)
 
 	switch format {
 	case "jpeg", "jpg", "gif":
 		// pass
 	case "png":
 
-		// START OF put me somewhere in aaronland/go-image ... maybe?
-		// trap fpdf "16-bit depth not supported in PNG file" errors
-
-		buf := new(bytes.Buffer)
+		// trap fpdf "16-bit depth not supported in PNG file" and "Interlacing not supported in PNG file" errors
 
-		err = png.Encode(buf, im)
+		convert, err := pngRequiresConversion(im_r)
 
 		if err != nil {
-			return fmt.Errorf("Failed to encode PNG image for %s, %w", abs_path, err)
+			return fmt.Errorf("Failed to read PNG header for %s, %w", abs_path, err)
 		}
 
-		// this bit is cribbed from https://github.com/jung-kurt/gofpdf/blob/7d57599b9d9c5fb48ea733596cbb812d7f84a8d6/png.go
-		// (20181231/thisisaaronland)
+		if convert && pb.Options.Debug {
+			logger.Debug("PNG would be converted to a JPG, skipping in debug mode")
+			break
+		}
 
-		_ = buf.Next(12)
+		if convert {
 
-		var bpc int32
-		err := binary.Read(buf, binary.BigEndian, &bpc)
+			err = ensureImage()
 
-		if err != nil {
-			return err
-		}
-
-		if bpc > 8 {
+			if err != nil {
+				return fmt.Errorf("Failed to decode image %s, %w", abs_path, err)
+			}
 
 			tmpfile_path, tmpfile_format, err := tempfile.TempFileWithImage(ctx, pb.Options.Temporary, im)
 
@@ -821,10 +1257,19 @@ func (pb *PictureBook) AddPicture(ctx context.Context, pagenum int, pic *picture
 			is_tempfile = true
 		}
 
-		// END OF put me somewhere in aaronland/go-image ... maybe?
-
 	case "webp", "tiff", "tif", "heic":
 
+		if pb.Options.Debug {
+			logger.Debug("Image would be converted to a JPG, skipping in debug mode")
+			break
+		}
+
+		err = ensureImage()
+
+		if err != nil {
+			return fmt.Errorf("Failed to decode image %s, %w", abs_path, err)
+		}
+
 		tmpfile_path, tmpfile_format, err := tempfile.TempFileWithImage(ctx, pb.Options.Temporary, im)
 
 		if err != nil {
@@ -842,48 +1287,44 @@ func (pb *PictureBook) AddPicture(ctx context.Context, pagenum int, pic *picture
 
 	default:
 
-		logger.Warn("Image format not supported yet, skipping", "format", format)
-		return nil
-	}
+		// Formats which would pass ensureSupportedFormat (for example because of an alias) but which are not handled
+		// above are still errors so that the picture is skipped, or the error policy is applied, rather than being lost
 
-	dims := im.Bounds()
+		return fmt.Errorf("%w (%s)", ErrUnsupportedFormat, format)
+	}
 
-	w := float64(dims.Max.X)
-	h := float64(dims.Max.Y)
+	w := float64(im_w)
+	h := float64(im_h)
 
 	logger.Debug("Dimensions", slog.Float64("width", w), slog.Float64("height", h))
 
-	if pb.Options.FillPage {
+	fill_page := pb.Options.FillPage
 
-		image_orientation := "U" // unknown
+	if pic.FillPage != nil {
+		fill_page = *pic.FillPage
+	}
 
-		if dims.Max.Y > dims.Max.X {
-			image_orientation = "P"
-		} else if dims.Max.X > dims.Max.Y {
-			image_orientation = "L"
-		} else {
-			// pass
-		}
+	if fill_page {
 
-		_, line_h := pb.PDF.GetFontSize()
+		rotate_to_fill := pb.Layout.RotateToFill(w, h)
 
-		max_w := pb.Canvas.Width
-		max_h := pb.Canvas.Height - (pb.Text.Margin + line_h)
+		if rotate_to_fill && pb.Options.Debug {
 
-		rotate_to_fill := false
+			logger.Debug("Image would be rotated to fill page, skipping in debug mode")
 
-		if pb.Options.Orientation == "P" && image_orientation == "L" && w > max_w {
-			rotate_to_fill = true
-		}
+			// Rotating an image 90 degrees swaps its dimensions
+			w, h = h, w
 
-		if pb.Options.Orientation == "L" && image_orientation == "P" && h > max_h {
-			rotate_to_fill = true
-		}
-
-		if rotate_to_fill {
+		} else if rotate_to_fill {
 
 			slog.Debug("Rotate image to fill path", "path", abs_path)
 
+			err := ensureImage()
+
+			if err != nil {
+				return fmt.Errorf("Failed to decode image %s, %w", abs_path, err)
+			}
+
 			new_im, err := rotate.RotateImageWithDegrees(ctx, im, 90.0)
 
 			if err != nil {
@@ -891,7 +1332,7 @@ func (pb *PictureBook) AddPicture(ctx context.Context, pagenum int, pic *picture
 			}
 
 			im = new_im
-			dims = im.Bounds()
+			dims := im.Bounds()
 
 			w = float64(dims.Max.X)
 			h = float64(dims.Max.Y)
@@ -924,292 +1365,381 @@ func (pb *PictureBook) AddPicture(ctx context.Context, pagenum int, pic *picture
 		}
 	}
 
-	// START OF adjust height relative to caption so that
-	// it (the caption) doesn't spill in to the margin
+	// The final dimensions, in pixels, of the image being added
 
-	caption_h := 0.0
+	pixel_w := int(w)
+	pixel_h := int(h)
 
-	if caption != "" {
+	placement, err := pb.Layout.Picture(w, h, caption)
 
-		lines := strings.Split(caption, "\n")
-		count := len(lines)
+	if err != nil {
+		return fmt.Errorf("[%d] Failed to place %s, %w", pagenum, abs_path, err)
+	}
 
-		font_sz, _ := pb.PDF.GetFontSize()
-		// pb.PDF.SetFontSize(font_sz + 2)
+	logger.Debug("final dimensions", slog.Float64("width", placement.Image.Width), slog.Float64("height", placement.Image.Height), slog.Float64("x", placement.Image.X), slog.Float64("y", placement.Image.Y))
 
-		line_h := font_sz + 2 // pb.PDF.GetFontSize()
+	var checksum string
 
-		caption_h = (float64(line_h) + pb.Text.Margin) * float64(count)
+	if pb.Options.Manifest != "" {
 
-		// slog.Info("CAPTION H", "l", line_h, "h", h, "caption", caption_h, "new", h - caption_h)
-	}
+		source_bucket, source_path := pb.pictureSource(pic)
 
-	// END OF adjust height relative to caption so that
+		sum, err := sha256Checksum(ctx, source_bucket, source_path)
+
+		if err != nil {
+			return fmt.Errorf("Failed to derive checksum for %s, %w", source_path, err)
+		}
 
-	opts := fpdf.ImageOptions{
-		ReadDpi:   false,
-		ImageType: format,
+		checksum = sum
 	}
 
-	var r io.ReadCloser
+	image_bucket := picture_bucket
 
 	if is_tempfile {
-		r, err = pb.Options.Temporary.NewReader(ctx, abs_path, nil)
-	} else {
-		r, err = picture_bucket.NewReader(ctx, abs_path, nil)
+		image_bucket = pb.Options.Temporary
+	}
+
+	page := &picture.PictureBookPage{
+		Type:         picture.PAGE_TYPE_PICTURE,
+		Source:       pic.Source,
+		Path:         pic.Path,
+		Caption:      caption,
+		Text:         pic.Text,
+		PixelWidth:   pixel_w,
+		PixelHeight:  pixel_h,
+		X:            placement.Image.X,
+		Y:            placement.Image.Y,
+		Width:        placement.Image.Width,
+		Height:       placement.Image.Height,
+		EffectiveDPI: effectiveDPI(pixel_w, placement.Image.Width),
+		Border:       placement.Border,
+		Lines:        placement.Lines,
+		Checksum:     checksum,
+		Bucket:       image_bucket,
+		Image:        abs_path,
+		Format:       format,
 	}
 
+	err = pb.addPage(ctx, page)
+
 	if err != nil {
-		return fmt.Errorf("Failed to create new reader (info) for %s, %v", abs_path, err)
+		return err
 	}
 
-	defer r.Close()
+	if pb.Options.Preflight != nil {
+		pb.preflightPicture(ctx, pic, page)
+	}
 
-	info := pb.PDF.RegisterImageOptionsReader(abs_path, opts, r)
+	return nil
+}
+
+// pictureSource returns the bucket and path of the original (unprocessed) image for 'pic'.
+func (pb *PictureBook) pictureSource(pic *picture.PictureBookPicture) (bucket.Bucket, string) {
 
-	if info == nil {
-		return fmt.Errorf("unable to determine info for %s with format (%s)", abs_path, format)
+	// Images added using the AddImage and AddReader methods only exist in memory
+
+	if pb.memory != nil && pic.Bucket == pb.memory {
+		return pb.memory, pic.Path
 	}
 
-	info.SetDpi(pb.Options.DPI)
+	return pb.Options.Source, strings.Split(pic.Source, "#")[0]
+}
 
-	logger.Debug("Dimensions", slog.Float64("width", w), slog.Float64("height", h))
+// Save will write the picturebook to 'path' in the `Target` bucket specified in the `PictureBookOptions`
+// used to create the picturebook option. The picturebook is written as a PDF document unless the `Output` option is set.
+func (pb *PictureBook) Save(ctx context.Context, path string) (err error) {
 
-	if w == 0.0 || h == 0.0 {
-		return fmt.Errorf("[%d] %s has zero-sized dimension", pagenum, abs_path)
+	if pb.Options.Target == nil {
+		return fmt.Errorf("Missing or invalid target bucket")
 	}
 
-	// Remember: margins have been calculated inclusive of page bleeds
+	defer pb.removeTempFiles(ctx)
 
-	margins := pb.Margins
+	t1 := time.Now()
 
-	x := margins.Left
-	y := margins.Top
+	d := progress.NewDispatcher(ctx, pb.Options.Monitor)
+	defer d.Close()
 
-	_, line_h := pb.PDF.GetFontSize()
+	save_tracker := progress.NewTracker(progress.STAGE_SAVE, 1)
+	d.Signal(ctx, save_tracker.Event(path))
 
-	logger.Debug("margins", slog.Float64("left_and_right", (margins.Left+margins.Right)))
-	logger.Debug("margins", slog.Float64("top_and_bottom", (margins.Top+margins.Bottom)))
-	logger.Debug("margins", slog.Float64("caption", (pb.Text.Margin+line_h)))
+	var size int64
 
-	max_w := pb.Canvas.Width
-	max_h := pb.Canvas.Height
+	defer func() {
 
-	// START OF adjust height relative to caption
-	// so that it (the caption) doesn't spill in to the margin
+		pb.saved(t1, size)
 
-	if caption != "" {
+		if err == nil {
+			d.Signal(ctx, save_tracker.Next(path))
+			d.Clear()
+		}
+	}()
 
-		lines := strings.Split(caption, "\n")
-		count := len(lines)
+	if pb.Options.Debug {
 
-		font_sz, _ := pb.PDF.GetFontSize()
-		line_h := font_sz + 2 // pb.PDF.GetFontSize()
+		format := pb.Options.Manifest
 
-		caption_h := (float64(line_h) + pb.Text.Margin) * float64(count)
-		max_h = max_h - caption_h
-	}
+		if format == "" {
+			format = MANIFEST_FORMAT_JSON
+		}
 
-	// END OF adjust height relative to caption
+		manifest_path := manifestPath(path, format)
 
-	logger.Debug("max dimensions", slog.Float64("max_width", max_w), slog.Float64("width", w), slog.Float64("max_height", max_h), slog.Float64("height", h))
+		slog.Debug("Save picturebook manifest (debug)", "path", manifest_path)
 
-	for {
+		err := pb.SaveManifest(ctx, manifest_path)
 
-		if w >= max_w || h >= max_h {
+		if err != nil {
+			return err
+		}
 
-			if w > max_w {
+		return pb.savePreviews(ctx)
+	}
 
-				ratio := max_w / w
-				w = max_w
-				h = h * ratio
+	if pb.Options.Output != nil {
 
-			}
+		slog.Debug("Save picturebook", "path", path, "output", fmt.Sprintf("%T", pb.Options.Output))
 
-			// slog.Info("CALC", "h", h, "max_h", max_h, "caption_h", caption_h)
+		err := pb.Options.Output.Save(ctx, pb.Options.Target, path, pb.Document())
 
-			if (h + caption_h) > max_h {
+		if err != nil {
+			return fmt.Errorf("Failed to save picturebook for %s, %w", path, err)
+		}
 
-				ratio := max_h / (h + caption_h)
-				w = w * ratio
-				h = max_h
-			}
+		size = pb.outputSize(ctx, path)
+
+	} else {
+
+		slog.Debug("Save picturebook", "path", path)
 
+		wr, err := pb.Options.Target.NewWriter(ctx, path, nil)
+
+		if err != nil {
+			return fmt.Errorf("Failed to create a new writer for %s, %w", path, err)
 		}
 
-		// TO DO: ENSURE ! h < max_h && ! w < max_w
+		err = pb.PDF.Output(wr)
 
-		if w <= max_w && h <= max_h {
-			break
+		if err != nil {
+			return fmt.Errorf("Failed to output PDF file for %s, %w", path, err)
 		}
-	}
 
-	if w < max_w {
-		padding := max_w - w
-		x = x + (padding / 2.0)
-	}
+		err = wr.Close()
 
-	if h < max_h {
-		padding := max_h - h
-		y = y + (padding / 2.0)
+		if err != nil {
+			return fmt.Errorf("Failed to close writer for %s, %w", path, err)
+		}
+
+		size = pb.outputSize(ctx, path)
 	}
 
-	logger.Debug("final dimensions", slog.Float64("width", w), slog.Float64("height", h), slog.Float64("x", x), slog.Float64("y", y))
+	if pb.Options.Manifest != "" {
 
-	pb.PDF.AddPage()
+		manifest_path := manifestPath(path, pb.Options.Manifest)
 
-	// logger.Debug("final dimensions %0.2f x %0.2f (%0.2f x %0.2f)", w, h, x, y)
+		slog.Debug("Save picturebook manifest", "path", manifest_path)
 
-	// draw margins
+		err := pb.SaveManifest(ctx, manifest_path)
 
-	mx := x / pb.Options.DPI
-	my := y / pb.Options.DPI
-	mw := w / pb.Options.DPI
-	mh := h / pb.Options.DPI
+		if err != nil {
+			return fmt.Errorf("Failed to save manifest for %s, %w", path, err)
+		}
+	}
 
-	logger.Debug("margin", slog.Float64("x", mx), slog.Float64("y", my), slog.Float64("width", mw), slog.Float64("height", mh))
+	return pb.savePreviews(ctx)
+}
 
-	pb.PDF.SetFillColor(0, 0, 0)
-	pb.PDF.Rect(mx, my, mw, mh, "FD")
+// SaveTo writes the picturebook to 'wr'. The picturebook is written as a PDF document unless the `Output` option is set,
+// in which case the output must implement the `output.WriterOutput` interface. Manifests are not written but previews
+// are, if the `Preview` option is set.
+func (pb *PictureBook) SaveTo(ctx context.Context, wr io.Writer) (err error) {
 
-	// draw borders
+	defer pb.removeTempFiles(ctx)
 
-	borders := pb.Borders
-	r_border := borders.Right
+	if pb.Options.Debug {
+		return fmt.Errorf("Picturebooks can not be written in debug mode")
+	}
 
-	if r_border > 0.0 {
+	t1 := time.Now()
 
-		bx := (x - borders.Left) / pb.Options.DPI
-		by := (y - borders.Top) / pb.Options.DPI
-		bw := (w + borders.Left + borders.Right) / pb.Options.DPI
-		bh := (h + borders.Top + borders.Bottom) / pb.Options.DPI
+	d := progress.NewDispatcher(ctx, pb.Options.Monitor)
+	defer d.Close()
 
-		logger.Debug("border", slog.Float64("x", bx), slog.Float64("y", by), slog.Float64("width", bw), slog.Float64("height", bh))
+	save_tracker := progress.NewTracker(progress.STAGE_SAVE, 1)
+	d.Signal(ctx, save_tracker.Event(""))
 
-		pb.PDF.SetFillColor(0, 0, 0)
-		pb.PDF.Rect(bx, by, bw, bh, "FD")
+	cw := &countingWriter{
+		wr: wr,
 	}
 
-	// draw the image
+	defer func() {
 
-	// https://godoc.org/github.com/jung-kurt/fpdf#ImageOptions
+		pb.saved(t1, cw.count)
 
-	image_opts := fpdf.ImageOptions{
-		ReadDpi:   false,
-		ImageType: format,
-	}
+		if err == nil {
+			d.Signal(ctx, save_tracker.Next(""))
+			d.Clear()
+		}
+	}()
+
+	wr = cw
 
-	image_x := x / pb.Options.DPI
-	image_y := y / pb.Options.DPI
-	image_w := w / pb.Options.DPI
-	image_h := h / pb.Options.DPI
+	if pb.Options.Output != nil {
 
-	logger.Debug("image", slog.Float64("x", image_x), slog.Float64("y", image_y), slog.Float64("width", image_w), slog.Float64("height", image_h))
+		o, ok := pb.Options.Output.(output.WriterOutput)
 
-	pb.PDF.ImageOptions(abs_path, image_x, image_y, image_w, image_h, false, image_opts, 0, "")
+		if !ok {
+			return fmt.Errorf("Output (%T) does not support writing to an io.Writer", pb.Options.Output)
+		}
+
+		slog.Debug("Save picturebook to writer", "output", fmt.Sprintf("%T", pb.Options.Output))
+
+		err := o.SaveTo(ctx, wr, pb.Document())
+
+		if err != nil {
+			return fmt.Errorf("Failed to save picturebook, %w", err)
+		}
 
-	if caption != "" {
+	} else {
 
-		current_x := x
-		current_y := y
+		slog.Debug("Save picturebook to writer")
 
-		for txt := range strings.SplitSeq(caption, "\n") {
+		err := pb.PDF.Output(wr)
 
-			txt = strings.TrimSpace(txt)
+		if err != nil {
+			return fmt.Errorf("Failed to output PDF file, %w", err)
+		}
+	}
 
-			txt_w := pb.PDF.GetStringWidth(txt)
-			txt_h := line_h
+	return pb.savePreviews(ctx)
+}
 
-			txt_w = txt_w + pb.Text.Margin
-			txt_h = txt_h + pb.Text.Margin
+// saved records the time since 't1' spent saving the picturebook and 'size', the size of the saved picturebook, in the picturebook's summary.
+func (pb *PictureBook) saved(t1 time.Time, size int64) {
 
-			// please do this in the constructor...
-			// (20171128/thisisaaronland)
+	pb.Mutex.Lock()
+	defer pb.Mutex.Unlock()
 
-			font_sz, _ := pb.PDF.GetFontSize()
-			pb.PDF.SetFontSize(font_sz + 2)
+	pb.timeStage(progress.STAGE_SAVE, t1)
 
-			_, line_h := pb.PDF.GetFontSize()
+	pb.summary.OutputSize = size
+	pb.summary.Duration = time.Since(pb.created)
+}
 
-			logger.Debug("line height", "height", fmt.Sprintf("%0.2f", line_h))
+// outputSize returns the size of the file at 'path' in the `Target` bucket or 0 if it can not be determined, for example
+// because the picturebook was written by an output, like HTML, which writes a directory of files.
+func (pb *PictureBook) outputSize(ctx context.Context, path string) int64 {
 
-			pb.PDF.SetFontSize(font_sz)
+	attrs, err := pb.Options.Target.Attributes(ctx, path)
 
-			txt_x := ((current_x + w) / pb.Options.DPI) - txt_w
-			txt_y := ((current_y + h) / pb.Options.DPI) + line_h
+	if err != nil {
+		slog.Debug("Failed to derive attributes for picturebook", "path", path, "error", err)
+		return 0
+	}
 
-			logger.Debug("Text", slog.Float64("x", txt_x), slog.Float64("y", txt_y), slog.Float64("width", txt_w), slog.Float64("height", txt_h))
+	return attrs.Size
+}
 
-			// pb.PDF.SetFillColor(255, 255, 255)
-			// pb.PDF.Rect(txt_x, txt_y, txt_w, txt_h, "FD")
+// removeTempFiles removes any temporary files created while adding images to the picturebook and any images held in memory.
+func (pb *PictureBook) removeTempFiles(ctx context.Context) {
 
-			pb.PDF.SetXY(txt_x, txt_y)
+	for _, path := range pb.tmpfiles {
 
-			logger.Debug("caption", "text", txt)
+		fname := filepath.Base(path)
 
-			html := pb.PDF.HTMLBasicNew()
-			html.Write(line_h, txt)
+		// This shouldn't be necessary and points to a larger problem
+		// but this bandaid-fix will have to do for now...
+		// (20210103/straup)
 
-			current_y += ((txt_h * pb.Options.DPI) * .65)
+		if !strings.HasPrefix(fname, "picturebook-") {
+			continue
 		}
-	}
 
-	return nil
-}
+		slog.Debug("Remove tmp file", "path", path)
 
-// Save will write the picturebook to 'path' in the `Target` bucket specified in the `PictureBookOptions`
-// used to create the picturebook option.
-func (pb *PictureBook) Save(ctx context.Context, path string) error {
+		err := pb.Options.Temporary.Delete(ctx, path)
 
-	if pb.Options.Target == nil {
-		return fmt.Errorf("Missing or invalid target bucket")
+		if err != nil {
+			slog.Error("Failed to delete tmp file", "path", path, "error", err)
+		}
 	}
 
-	// move this out of here...
+	pb.tmpfiles = make([]string, 0)
 
-	defer func() {
+	// Images added using the AddImage and AddReader methods are only needed until the picturebook has been saved
+
+	pb.Mutex.Lock()
+	defer pb.Mutex.Unlock()
 
-		for _, path := range pb.tmpfiles {
+	if pb.memory != nil {
 
-			fname := filepath.Base(path)
+		slog.Debug("Remove in-memory images")
 
-			// This shouldn't be necessary and points to a larger problem
-			// but this bandaid-fix will have to do for now...
-			// (20210103/straup)
+		err := pb.memory.Close()
 
-			if !strings.HasPrefix(fname, "picturebook-") {
-				continue
-			}
+		if err != nil {
+			slog.Error("Failed to close in-memory bucket", "error", err)
+		}
 
-			slog.Debug("Remove tmp file", "path", path)
+		pb.memory = nil
+	}
+}
 
-			err := pb.Options.Temporary.Delete(ctx, path)
+// savePreviews writes PNG previews of each page, and a contact sheet of all the pages, to the `Preview` bucket
+// specified in the `PictureBookOptions` used to create the picturebook. If the `Preview` bucket is nil this method
+// does nothing.
+func (pb *PictureBook) savePreviews(ctx context.Context) error {
 
-			if err != nil {
-				slog.Error("Failed to delete tmp file", "path", path, "error", err)
-			}
-		}
-	}()
+	if pb.Options.Preview == nil {
+		return nil
+	}
 
-	slog.Debug("Save picturebook", "path", path)
+	slog.Debug("Save picturebook previews", "dpi", pb.Options.PreviewDPI)
 
-	wr, err := pb.Options.Target.NewWriter(ctx, path, nil)
+	err := preview.SavePreviews(ctx, pb.Options.Preview, pb.Document(), pb.Options.PreviewDPI)
 
 	if err != nil {
-		return fmt.Errorf("Failed to create a new writer for %s, %w", path, err)
+		return fmt.Errorf("Failed to save previews, %w", err)
 	}
 
-	err = pb.PDF.Output(wr)
+	return nil
+}
 
-	if err != nil {
-		return fmt.Errorf("Failed to output PDF file for %s, %w", path, err)
+// Document returns an `output.Document` instance describing the picturebook and each of its pages.
+func (pb *PictureBook) Document() *output.Document {
+
+	w, h := pb.PDF.GetPageSize()
+
+	doc := &output.Document{
+		Title:  pb.Options.Title,
+		Width:  w,
+		Height: h,
+		DPI:    pb.Options.DPI,
+		Pages:  pb.Pages(),
 	}
 
-	err = wr.Close()
+	return doc
+}
+
+// addPage draws 'page' in the picturebook's PDF document, if pages are being rendered, and then records it in the picturebook's
+// manifest. Pages which fail to be drawn are not recorded. It is expected to be called while holding `pb.Mutex`.
+func (pb *PictureBook) addPage(ctx context.Context, page *picture.PictureBookPage) error {
 
-	if err != nil {
-		return fmt.Errorf("Failed to close writer for %s, %w", path, err)
+	if pb.renderPDF() {
+
+		err := pb.Renderer.AddPage(ctx, page)
+
+		if err != nil {
+			return err
+		}
 	}
 
+	pb.recordPage(page)
 	return nil
 }
+
+// renderPDF reports whether pages should be rendered in the picturebook's PDF document. Pages are not rendered
+// in debug mode or when the picturebook is being written using a custom `output.Output` instance.
+func (pb *PictureBook) renderPDF() bool {
+	return !pb.Options.Debug && pb.Options.Output == nil
+}
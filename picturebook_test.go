@@ -0,0 +1,43 @@
+package picturebook
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"testing"
+)
+
+func TestSaveTo(t *testing.T) {
+
+	ctx := context.Background()
+
+	opts, err := NewPictureBookDefaultOptions(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to create default options, %v", err)
+	}
+
+	pb, err := NewPictureBook(ctx, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create picturebook, %v", err)
+	}
+
+	err = pb.AddImage(ctx, image.NewRGBA(image.Rect(0, 0, 40, 20)), nil)
+
+	if err != nil {
+		t.Fatalf("Failed to add image, %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	err = pb.SaveTo(ctx, &buf)
+
+	if err != nil {
+		t.Fatalf("Failed to save picturebook, %v", err)
+	}
+
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("Picturebook is not a PDF document")
+	}
+}
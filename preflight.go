@@ -0,0 +1,177 @@
+package picturebook
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"log/slog"
+	"math"
+
+	"github.com/aaronland/go-picturebook/picture"
+)
+
+// PREFLIGHT_LOW_RESOLUTION is the preflight check for images whose effective resolution is below `PreflightOptions.MinDPI`.
+const PREFLIGHT_LOW_RESOLUTION string = "low-resolution"
+
+// PREFLIGHT_UPSCALED is the preflight check for images which have been enlarged, either by a process or by being placed on the
+// page at a size which, at the picturebook's DPI, needs more pixels than the original image has.
+const PREFLIGHT_UPSCALED string = "upscaled"
+
+// PREFLIGHT_ASPECT_RATIO is the preflight check for images whose aspect ratio exceeds `PreflightOptions.MaxAspectRatio`.
+const PREFLIGHT_ASPECT_RATIO string = "aspect-ratio"
+
+// PREFLIGHT_CMYK is the preflight check for images which use the CMYK colour model.
+const PREFLIGHT_CMYK string = "cmyk"
+
+// PREFLIGHT_16BIT is the preflight check for images which use 16 bits per colour channel.
+const PREFLIGHT_16BIT string = "16-bit"
+
+// DEFAULT_PREFLIGHT_MIN_DPI is the default minimum effective resolution, in dots per inch, for images in a picturebook.
+const DEFAULT_PREFLIGHT_MIN_DPI float64 = 300.0
+
+// DEFAULT_PREFLIGHT_MAX_ASPECT_RATIO is the default maximum ratio of the longest side of an image to its shortest side.
+const DEFAULT_PREFLIGHT_MAX_ASPECT_RATIO float64 = 3.0
+
+// type PreflightOptions defines a struct containing the settings used to check images added to a picturebook for problems.
+type PreflightOptions struct {
+	// The minimum effective resolution, in dots per inch, of an image placed on a page. If 0 resolution is not checked.
+	MinDPI float64 `json:"min_dpi"`
+	// The maximum ratio of the longest side of an image to its shortest side. If 0 aspect ratios are not checked.
+	MaxAspectRatio float64 `json:"max_aspect_ratio"`
+}
+
+// type PreflightWarning defines a struct containing details about a problem found with an image added to a picturebook.
+type PreflightWarning struct {
+	// The number of the page the image was placed on.
+	Page int `json:"page"`
+	// The original (relative) path of the image.
+	Source string `json:"source"`
+	// The name of the check which failed. One of the PREFLIGHT_* constants.
+	Check string `json:"check"`
+	// A description of the problem.
+	Message string `json:"message"`
+	// The effective resolution, in dots per inch, of the image placed on the page. This is the same as the page's `EffectiveDPI` property.
+	EffectiveDPI float64 `json:"effective_dpi"`
+}
+
+// NewPreflightDefaultOptions returns a `PreflightOptions` with default settings.
+func NewPreflightDefaultOptions() *PreflightOptions {
+
+	opts := &PreflightOptions{
+		MinDPI:         DEFAULT_PREFLIGHT_MIN_DPI,
+		MaxAspectRatio: DEFAULT_PREFLIGHT_MAX_ASPECT_RATIO,
+	}
+
+	return opts
+}
+
+// effectiveDPI returns the effective resolution, in dots per inch, of an image 'pixel_width' pixels wide placed on a page
+// 'placed_width' inches wide. If 'placed_width' is not greater than 0 then 0 is returned.
+func effectiveDPI(pixel_width int, placed_width float64) float64 {
+
+	if placed_width <= 0 {
+		return 0.0
+	}
+
+	return float64(pixel_width) / placed_width
+}
+
+// PreflightWarnings returns the list of problems found with the images added to the picturebook, in the order they were added.
+// The list is always empty if the `Preflight` option is nil.
+func (pb *PictureBook) PreflightWarnings() []*PreflightWarning {
+	return pb.preflight
+}
+
+// preflightPicture checks the original image for 'pic', placed on 'page', for problems using the settings in the `Preflight`
+// option and records a `PreflightWarning` for each problem found. It is expected to be called while holding `pb.Mutex`.
+func (pb *PictureBook) preflightPicture(ctx context.Context, pic *picture.PictureBookPicture, page *picture.PictureBookPage) {
+
+	opts := pb.Options.Preflight
+
+	logger := slog.Default()
+	logger = logger.With("path", pic.Source)
+	logger = logger.With("page", page.Page)
+
+	// Start with the dimensions of the image placed on the page and then try to read those of the original image,
+	// which may have been processed, converted or rotated
+
+	source_w := page.PixelWidth
+	source_h := page.PixelHeight
+
+	var color_model color.Model
+
+	source_bucket, source_path := pb.pictureSource(pic)
+
+	r, err := source_bucket.NewReader(ctx, source_path, nil)
+
+	if err != nil {
+		logger.Warn("Failed to open original image for preflight checks", "error", err)
+	} else {
+
+		defer r.Close()
+
+		im_cfg, err := decodeImageConfig(ctx, r)
+
+		if err != nil {
+			logger.Debug("Failed to decode original image config for preflight checks", "error", err)
+		} else {
+			source_w = im_cfg.Width
+			source_h = im_cfg.Height
+			color_model = im_cfg.ColorModel
+		}
+	}
+
+	// Compare the longest sides so that images which have been rotated to fill the page are measured correctly
+
+	source_long := float64(max(source_w, source_h))
+	source_short := float64(min(source_w, source_h))
+
+	// Use the same effective resolution as the page's manifest so that both reports agree
+
+	effective_dpi := page.EffectiveDPI
+
+	warn := func(check string, msg string) {
+
+		logger.Debug("Preflight check failed", "check", check, "message", msg)
+
+		w := &PreflightWarning{
+			Page:         page.Page,
+			Source:       pic.Source,
+			Check:        check,
+			Message:      msg,
+			EffectiveDPI: effective_dpi,
+		}
+
+		pb.preflight = append(pb.preflight, w)
+	}
+
+	if opts.MinDPI > 0 && effective_dpi > 0 && effective_dpi < opts.MinDPI {
+		warn(PREFLIGHT_LOW_RESOLUTION, fmt.Sprintf("Effective resolution of %.0f DPI is below the minimum of %.0f DPI", effective_dpi, opts.MinDPI))
+	}
+
+	// An image is upscaled if it has been enlarged, for example by a process, or if printing it at its placed size at the
+	// picturebook's resolution needs more pixels than the original image has
+
+	required_w := max(page.PixelWidth, int(math.Round(page.Width*pb.Options.DPI)))
+	required_h := max(page.PixelHeight, int(math.Round(page.Height*pb.Options.DPI)))
+
+	if float64(max(required_w, required_h)) > source_long {
+		warn(PREFLIGHT_UPSCALED, fmt.Sprintf("Image has been enlarged from %d x %d to %d x %d pixels at %.0f DPI", source_w, source_h, required_w, required_h, pb.Options.DPI))
+	}
+
+	if opts.MaxAspectRatio > 0 && source_short > 0 {
+
+		ratio := source_long / source_short
+
+		if ratio > opts.MaxAspectRatio {
+			warn(PREFLIGHT_ASPECT_RATIO, fmt.Sprintf("Aspect ratio of %.1f:1 exceeds the maximum of %.1f:1", ratio, opts.MaxAspectRatio))
+		}
+	}
+
+	switch color_model {
+	case color.CMYKModel:
+		warn(PREFLIGHT_CMYK, "Image uses the CMYK colour model")
+	case color.RGBA64Model, color.NRGBA64Model, color.Gray16Model:
+		warn(PREFLIGHT_16BIT, "Image uses 16 bits per colour channel")
+	}
+}
@@ -0,0 +1,124 @@
+package picturebook
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/aaronland/go-picturebook/picture"
+)
+
+func TestPreflight(t *testing.T) {
+
+	ctx := context.Background()
+
+	pb, _ := newTestPictureBook(t, func(opts *PictureBookOptions) {
+		opts.Preflight = NewPreflightDefaultOptions()
+	})
+
+	// Images which are smaller than the page are placed at the picturebook's DPI (150) which is below the default minimum (300)
+
+	// AddImage converts 16-bit images to 8-bit images so encode this one ourselves
+
+	var buf bytes.Buffer
+
+	err := png.Encode(&buf, image.NewRGBA64(image.Rect(0, 0, 40, 20)))
+
+	if err != nil {
+		t.Fatalf("Failed to encode PNG, %v", err)
+	}
+
+	err = pb.AddReader(ctx, &buf, nil)
+
+	if err != nil {
+		t.Fatalf("Failed to add reader, %v", err)
+	}
+
+	err = pb.AddImage(ctx, image.NewRGBA(image.Rect(0, 0, 100, 20)), nil)
+
+	if err != nil {
+		t.Fatalf("Failed to add image, %v", err)
+	}
+
+	checks := make(map[int][]string)
+
+	pages := pb.Pages()
+
+	for _, w := range pb.PreflightWarnings() {
+
+		checks[w.Page] = append(checks[w.Page], w.Check)
+
+		// Preflight warnings and the manifest report the same effective resolution
+
+		if w.EffectiveDPI != pages[w.Page-1].EffectiveDPI {
+			t.Fatalf("Preflight DPI %f does not match manifest DPI %f for page %d", w.EffectiveDPI, pages[w.Page-1].EffectiveDPI, w.Page)
+		}
+	}
+
+	expected := map[int][]string{
+		1: {PREFLIGHT_LOW_RESOLUTION, PREFLIGHT_16BIT},
+		2: {PREFLIGHT_LOW_RESOLUTION, PREFLIGHT_ASPECT_RATIO},
+	}
+
+	for page, e := range expected {
+
+		if !slices.Equal(checks[page], e) {
+			t.Fatalf("Unexpected preflight checks for page %d: %v", page, checks[page])
+		}
+	}
+
+	if len(checks) != len(expected) {
+		t.Fatalf("Unexpected preflight warnings: %v", checks)
+	}
+}
+
+func TestPreflightUpscaled(t *testing.T) {
+
+	ctx := context.Background()
+
+	pb, _ := newTestPictureBook(t, func(opts *PictureBookOptions) {
+		opts.Preflight = NewPreflightDefaultOptions()
+	})
+
+	root := t.TempDir()
+
+	writeTestFiles(t, root, map[string]string{
+		"small.png": "",
+	})
+
+	// A 40 x 20 pixel image stretched across an 8 x 4 inch area needs 1200 x 600 pixels at 150 DPI
+
+	pic := &picture.PictureBookPicture{
+		Source: filepath.Join(root, "small.png"),
+		Path:   filepath.Join(root, "small.png"),
+	}
+
+	page := &picture.PictureBookPage{
+		Type:         picture.PAGE_TYPE_PICTURE,
+		Page:         1,
+		Source:       pic.Source,
+		PixelWidth:   40,
+		PixelHeight:  20,
+		Width:        8.0,
+		Height:       4.0,
+		EffectiveDPI: effectiveDPI(40, 8.0),
+	}
+
+	pb.Mutex.Lock()
+	pb.preflightPicture(ctx, pic, page)
+	pb.Mutex.Unlock()
+
+	checks := make([]string, 0)
+
+	for _, w := range pb.PreflightWarnings() {
+		checks = append(checks, w.Check)
+	}
+
+	if !slices.Equal(checks, []string{PREFLIGHT_LOW_RESOLUTION, PREFLIGHT_UPSCALED}) {
+		t.Fatalf("Unexpected preflight checks: %v", checks)
+	}
+}
@@ -0,0 +1,292 @@
+// package preview provides methods for rendering low-resolution images of the pages in a picturebook without
+// the need for a PDF rasteriser.
+package preview
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"log/slog"
+	"math"
+	"sync"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/output"
+	"github.com/aaronland/go-picturebook/picture"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// The default number of dots per inch at which previews are rendered.
+const DEFAULT_DPI float64 = 36.0
+
+// The number of pages in each row of a contact sheet.
+const CONTACT_SHEET_COLUMNS int = 6
+
+// The number of pixels between (and around) the pages in a contact sheet.
+const CONTACT_SHEET_GUTTER int = 10
+
+// The filename of the contact sheet written by `SavePreviews`.
+const CONTACT_SHEET_FILENAME string = "contact-sheet.png"
+
+var preview_font *opentype.Font
+
+var preview_font_once sync.Once
+
+var preview_font_err error
+
+// loadFont parses (once) the font used to render text in previews.
+func loadFont() (*opentype.Font, error) {
+
+	preview_font_once.Do(func() {
+		preview_font, preview_font_err = opentype.Parse(goregular.TTF)
+	})
+
+	return preview_font, preview_font_err
+}
+
+// RenderPage renders 'page' of 'doc' as an image at 'dpi' dots per inch. The page is drawn using the same geometry
+// (image, border and text positions) recorded for the page when it was added to the picturebook's PDF document.
+func RenderPage(ctx context.Context, doc *output.Document, page *picture.PictureBookPage, dpi float64) (*image.RGBA, error) {
+
+	px := func(v float64) int {
+		return int(math.Round(v * dpi))
+	}
+
+	im := image.NewRGBA(image.Rect(0, 0, px(doc.Width), px(doc.Height)))
+	draw.Draw(im, im.Bounds(), image.White, image.Point{}, draw.Src)
+
+	if page.Type == picture.PAGE_TYPE_PICTURE {
+
+		image_rect := image.Rect(px(page.X), px(page.Y), px(page.X+page.Width), px(page.Y+page.Height))
+
+		draw.Draw(im, image_rect, image.Black, image.Point{}, draw.Src)
+
+		if page.Border != nil {
+			b := page.Border
+			border_rect := image.Rect(px(b.X), px(b.Y), px(b.X+b.Width), px(b.Y+b.Height))
+			draw.Draw(im, border_rect, image.Black, image.Point{}, draw.Src)
+		}
+
+		src, err := pageImage(ctx, page)
+
+		if err != nil {
+			// Leave the (black) image box in place so that the layout is still visible
+			slog.Warn("Failed to read image for preview", "page", page.Page, "image", page.Image, "error", err)
+		} else {
+			draw.ApproxBiLinear.Scale(im, image_rect, src, src.Bounds(), draw.Src, nil)
+		}
+	}
+
+	err := drawLines(im, page.Lines, dpi)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to draw text for page %d, %w", page.Page, err)
+	}
+
+	return im, nil
+}
+
+// drawLines draws each of the lines of text in 'lines' on 'im' at 'dpi' dots per inch.
+func drawLines(im *image.RGBA, lines []*picture.PictureBookTextRun, dpi float64) error {
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	f, err := loadFont()
+
+	if err != nil {
+		return fmt.Errorf("Failed to load font, %w", err)
+	}
+
+	faces := make(map[float64]font.Face)
+
+	defer func() {
+		for _, face := range faces {
+			face.Close()
+		}
+	}()
+
+	for _, ln := range lines {
+
+		face, ok := faces[ln.Size]
+
+		if !ok {
+
+			face, err = opentype.NewFace(f, &opentype.FaceOptions{
+				Size:    ln.Size,
+				DPI:     dpi,
+				Hinting: font.HintingNone,
+			})
+
+			if err != nil {
+				return fmt.Errorf("Failed to create font face, %w", err)
+			}
+
+			faces[ln.Size] = face
+		}
+
+		// Text is vertically centered within the line, with its baseline 0.3 x the font size below
+		// the center of the line, as it is in PDF documents
+
+		baseline := (ln.Y + (ln.Height / 2.0) + (0.3 * ln.Size / 72.0)) * dpi
+
+		d := &font.Drawer{
+			Dst:  im,
+			Src:  image.Black,
+			Face: face,
+			Dot:  fixed.P(int(math.Round(ln.X*dpi)), int(math.Round(baseline))),
+		}
+
+		d.DrawString(ln.Text)
+	}
+
+	return nil
+}
+
+// pageImage reads and decodes the final image placed on 'page'.
+func pageImage(ctx context.Context, page *picture.PictureBookPage) (image.Image, error) {
+
+	if page.Bucket == nil || page.Image == "" {
+		return nil, fmt.Errorf("Page is missing an image")
+	}
+
+	r, err := page.Bucket.NewReader(ctx, page.Image, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create new reader for %s, %w", page.Image, err)
+	}
+
+	defer r.Close()
+
+	im, _, err := image.Decode(r)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode %s, %w", page.Image, err)
+	}
+
+	return im, nil
+}
+
+// ContactSheet returns a new image containing each of the images in 'pages' arranged in a grid 'columns' wide. All
+// the cells in the grid are the size of the largest image in 'pages'.
+func ContactSheet(pages []image.Image, columns int) *image.RGBA {
+
+	columns = max(1, min(columns, len(pages)))
+	rows := (len(pages) + columns - 1) / columns
+
+	cell_w := 0
+	cell_h := 0
+
+	for _, p := range pages {
+		cell_w = max(cell_w, p.Bounds().Dx())
+		cell_h = max(cell_h, p.Bounds().Dy())
+	}
+
+	gutter := CONTACT_SHEET_GUTTER
+
+	w := (columns * cell_w) + ((columns + 1) * gutter)
+	h := (rows * cell_h) + ((rows + 1) * gutter)
+
+	sheet := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(color.Gray{Y: 0xdd}), image.Point{}, draw.Src)
+
+	for i, p := range pages {
+
+		x := gutter + ((i % columns) * (cell_w + gutter))
+		y := gutter + ((i / columns) * (cell_h + gutter))
+
+		r := image.Rect(x, y, x+p.Bounds().Dx(), y+p.Bounds().Dy())
+		draw.Draw(sheet, r, p, p.Bounds().Min, draw.Src)
+	}
+
+	return sheet
+}
+
+// SavePreviews renders each of the pages in 'doc' at 'dpi' dots per inch and writes them to 'target' as PNG images
+// named "page-{NUMBER}.png", where {NUMBER} is the zero-padded page number. A contact sheet of all the pages is
+// written to 'target' as CONTACT_SHEET_FILENAME.
+func SavePreviews(ctx context.Context, target bucket.Bucket, doc *output.Document, dpi float64) error {
+
+	if dpi <= 0.0 {
+		dpi = DEFAULT_DPI
+	}
+
+	pages := make([]image.Image, 0, len(doc.Pages))
+
+	for _, p := range doc.Pages {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// pass
+		}
+
+		im, err := RenderPage(ctx, doc, p, dpi)
+
+		if err != nil {
+			return fmt.Errorf("Failed to render page %d, %w", p.Page, err)
+		}
+
+		err = writePNG(ctx, target, fmt.Sprintf("page-%04d.png", p.Page), im)
+
+		if err != nil {
+			return err
+		}
+
+		pages = append(pages, im)
+	}
+
+	if len(pages) == 0 {
+		return nil
+	}
+
+	sheet := ContactSheet(pages, CONTACT_SHEET_COLUMNS)
+
+	return writePNG(ctx, target, CONTACT_SHEET_FILENAME, sheet)
+}
+
+// writePNG writes 'im' encoded as a PNG image to 'path' in 'target'.
+func writePNG(ctx context.Context, target bucket.Bucket, path string, im image.Image) error {
+
+	var buf bytes.Buffer
+
+	err := png.Encode(&buf, im)
+
+	if err != nil {
+		return fmt.Errorf("Failed to encode %s, %w", path, err)
+	}
+
+	wr, err := target.NewWriter(ctx, path, nil)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create a new writer for %s, %w", path, err)
+	}
+
+	_, err = wr.Write(buf.Bytes())
+
+	if err != nil {
+		wr.Close()
+		return fmt.Errorf("Failed to write %s, %w", path, err)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		return fmt.Errorf("Failed to close writer for %s, %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package preview
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/output"
+	"github.com/aaronland/go-picturebook/picture"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestRenderPage(t *testing.T) {
+
+	ctx := context.Background()
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to register gocloud buckets, %v", err)
+	}
+
+	b, err := bucket.NewBucket(ctx, fmt.Sprintf("file://%s?metadata=skip", t.TempDir()))
+
+	if err != nil {
+		t.Fatalf("Failed to create bucket, %v", err)
+	}
+
+	defer b.Close()
+
+	red := image.NewRGBA(image.Rect(0, 0, 20, 10))
+
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 10; y++ {
+			red.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	wr, err := b.NewWriter(ctx, "red.png", nil)
+
+	if err != nil {
+		t.Fatalf("Failed to create writer, %v", err)
+	}
+
+	err = png.Encode(wr, red)
+
+	if err != nil {
+		t.Fatalf("Failed to encode image, %v", err)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close writer, %v", err)
+	}
+
+	page := &picture.PictureBookPage{
+		Page:   1,
+		Type:   picture.PAGE_TYPE_PICTURE,
+		X:      1.0,
+		Y:      1.0,
+		Width:  2.0,
+		Height: 1.0,
+		Border: &picture.PictureBookBox{X: 0.9, Y: 0.9, Width: 2.2, Height: 1.2},
+		Lines: []*picture.PictureBookTextRun{
+			{Text: "Caption", X: 2.0, Y: 2.2, Height: 0.2, Size: 36.0},
+		},
+		Bucket: b,
+		Image:  "red.png",
+		Format: "png",
+	}
+
+	doc := &output.Document{
+		Width:  4.0,
+		Height: 4.0,
+		Pages:  []*picture.PictureBookPage{page},
+	}
+
+	im, err := RenderPage(ctx, doc, page, 10.0)
+
+	if err != nil {
+		t.Fatalf("Failed to render page, %v", err)
+	}
+
+	if im.Bounds().Dx() != 40 || im.Bounds().Dy() != 40 {
+		t.Fatalf("Unexpected preview dimensions: %v", im.Bounds())
+	}
+
+	tests := map[image.Point]color.RGBA{
+		image.Pt(0, 0):   {R: 255, G: 255, B: 255, A: 255}, // page
+		image.Pt(9, 9):   {A: 255},                         // border
+		image.Pt(15, 15): {R: 255, A: 255},                 // image
+		image.Pt(35, 35): {R: 255, G: 255, B: 255, A: 255}, // page
+	}
+
+	for pt, expected := range tests {
+
+		c := im.RGBAAt(pt.X, pt.Y)
+
+		if c != expected {
+			t.Fatalf("Unexpected colour at %v, expected %v but got %v", pt, expected, c)
+		}
+	}
+
+	// Ensure the caption was drawn somewhere in its line
+
+	found := false
+
+	for x := 20; x < 40 && !found; x++ {
+		for y := 22; y < 26 && !found; y++ {
+			if im.RGBAAt(x, y).R < 200 {
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		t.Fatalf("Expected caption to be drawn")
+	}
+
+	sheet := ContactSheet([]image.Image{im, im, im}, 2)
+
+	expected_w := (2 * 40) + (3 * CONTACT_SHEET_GUTTER)
+	expected_h := (2 * 40) + (3 * CONTACT_SHEET_GUTTER)
+
+	if sheet.Bounds().Dx() != expected_w || sheet.Bounds().Dy() != expected_h {
+		t.Fatalf("Unexpected contact sheet dimensions: %v", sheet.Bounds())
+	}
+}
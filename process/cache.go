@@ -0,0 +1,109 @@
+package process
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/tempfile"
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// CacheKey returns a content-addressed key for the result of applying the processes defined by 'uris', in order,
+// to the file 'path' in 'source_bucket'. The key is the SHA-256 hash of the file's contents followed by each URI.
+func CacheKey(ctx context.Context, source_bucket bucket.Bucket, path string, uris []string) (string, error) {
+
+	r, err := source_bucket.NewReader(ctx, path, nil)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to create new reader for %s, %w", path, err)
+	}
+
+	defer r.Close()
+
+	body_h := sha256.New()
+
+	_, err = io.Copy(body_h, r)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to hash %s, %w", path, err)
+	}
+
+	h := sha256.New()
+	h.Write(body_h.Sum(nil))
+
+	for _, uri := range uris {
+		h.Write([]byte("\n"))
+		h.Write([]byte(uri))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fromCache copies the record named 'key' in 'cache_bucket', if present, to a new temporary file in 'target_bucket'
+// returning its path. If 'key' is not present in 'cache_bucket' an empty string is returned.
+func fromCache(ctx context.Context, cache_bucket bucket.Bucket, target_bucket bucket.Bucket, key string) (string, error) {
+
+	_, err := cache_bucket.Attributes(ctx, key)
+
+	if err != nil {
+		return "", nil
+	}
+
+	r, err := cache_bucket.NewReader(ctx, key, nil)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to create new reader for cached file %s, %w", key, err)
+	}
+
+	defer r.Close()
+
+	mtype, err := mimetype.DetectReader(r)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to detect mimetype for cached file %s, %w", key, err)
+	}
+
+	_, err = r.Seek(0, io.SeekStart)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to rewind cached file %s, %w", key, err)
+	}
+
+	return tempfile.TempFileWithReader(ctx, target_bucket, r, mtype.Extension())
+}
+
+// toCache copies the record named 'path' in 'source_bucket' to a record named 'key' in 'cache_bucket'.
+func toCache(ctx context.Context, source_bucket bucket.Bucket, cache_bucket bucket.Bucket, path string, key string) error {
+
+	r, err := source_bucket.NewReader(ctx, path, nil)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create new reader for %s, %w", path, err)
+	}
+
+	defer r.Close()
+
+	wr, err := cache_bucket.NewWriter(ctx, key, nil)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create new writer for cached file %s, %w", key, err)
+	}
+
+	_, err = io.Copy(wr, r)
+
+	if err != nil {
+		return fmt.Errorf("Failed to copy %s to cache, %w", path, err)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		return fmt.Errorf("Failed to close writer for cached file %s, %w", key, err)
+	}
+
+	return nil
+}
@@ -8,14 +8,70 @@ import (
 	"github.com/aaronland/go-image/v2/colour"
 	"github.com/aaronland/go-image/v2/decode"
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 	"github.com/aaronland/go-picturebook/tempfile"
 )
 
 func init() {
 
 	ctx := context.Background()
-	RegisterProcess(ctx, "colorspace", NewColourSpaceProcess)
-	RegisterProcess(ctx, "colourspace", NewColourSpaceProcess)
+	err := RegisterProcess(ctx, "colorspace", NewColourSpaceProcess)
+
+	if err != nil {
+		panic(err)
+	}
+
+	err = RegisterProcess(ctx, "colourspace", NewColourSpaceProcess)
+
+	if err != nil {
+		panic(err)
+	}
+
+	err = handler.RegisterDescription(ctx, handler.PROCESS, &handler.Description{
+		Scheme:  "colorspace",
+		Summary: "Map all the pixels in an image to a given colour space. This is an alias of colourspace://.",
+		Syntax:  "colorspace://{PROFILE}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "profile",
+				Location:    handler.PARAMETER_HOST,
+				Type:        "string",
+				Description: "The colour space to map pixels to: Adobe RGB or Apple's Display P3.",
+				Required:    true,
+				Values:      []string{"adobergb", "displayp3"},
+			},
+		},
+		Examples: []string{
+			"colorspace://displayp3",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	err = handler.RegisterDescription(ctx, handler.PROCESS, &handler.Description{
+		Scheme:  "colourspace",
+		Summary: "Map all the pixels in an image to a given colour space.",
+		Syntax:  "colourspace://{PROFILE}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "profile",
+				Location:    handler.PARAMETER_HOST,
+				Type:        "string",
+				Description: "The colour space to map pixels to: Adobe RGB or Apple's Display P3.",
+				Required:    true,
+				Values:      []string{"adobergb", "displayp3"},
+			},
+		},
+		Examples: []string{
+			"colourspace://adobergb",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 
 }
 
@@ -11,6 +11,7 @@ import (
 	"github.com/aaronland/go-image-contour/v2"
 	"github.com/aaronland/go-image/v2/decode"
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 	"github.com/aaronland/go-picturebook/tempfile"
 )
 
@@ -22,6 +23,36 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.PROCESS, &handler.Description{
+		Scheme:  "contour",
+		Summary: "Convert JPEG images in to a series of black and white \"contour\" lines.",
+		Syntax:  "contour://?iterations={INTEGER}&scale={FLOAT}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "iterations",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "integer",
+				Description: "The number of iterations to perform during the contour process.",
+				Default:     "12",
+			},
+			{
+				Name:        "scale",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "float",
+				Description: "The scale of the final contoured image.",
+				Default:     "1.0",
+			},
+		},
+		Examples: []string{
+			"contour://",
+			"contour://?iterations=8&scale=2.0",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // type ContourProcess implements the `Process` interface and transforms an image in to a series of black and white "contour" lines.
@@ -8,6 +8,7 @@ import (
 	"github.com/aaronland/go-image-halftone/v2"
 	"github.com/aaronland/go-image/v2/decode"
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 	"github.com/aaronland/go-picturebook/tempfile"
 )
 
@@ -19,6 +20,19 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.PROCESS, &handler.Description{
+		Scheme:  "halftone",
+		Summary: "Apply a \"halftone\" dithering transformation to an image.",
+		Syntax:  "halftone://",
+		Examples: []string{
+			"halftone://",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // type HalftoneProcess implements the `Process` interface and applies a "halftone" dithering transformation to an image.
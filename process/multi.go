@@ -3,31 +3,106 @@ package process
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/aaronland/go-picturebook/bucket"
 )
 
+// MultiProcessOptions defines configuration options for creating a new `MultiProcess` instance.
+type MultiProcessOptions struct {
+	// The `Process` instances used to transform an image, applied in order.
+	Processes []Process
+	// The URIs used to create each of the `Process` instances in `Processes`. These are used to derive cache keys and are required if `Cache` is not nil.
+	URIs []string
+	// An optional `bucket.Bucket` instance where the final results of applying `Processes` are cached, keyed by the contents of the source image and `URIs`.
+	Cache bucket.Bucket
+}
+
 // type MultiProcess implements the `Process` interface and allows multiple `Process` instances to
 // to tranform an image before adding it to a picturebook.
 type MultiProcess struct {
 	Process
 	processes []Process
+	uris      []string
+	cache     bucket.Bucket
 }
 
 // NewMultiProcess returns a new instance of `MultiProcess` for 'processes'
 func NewMultiProcess(ctx context.Context, processes ...Process) (Process, error) {
 
+	opts := &MultiProcessOptions{
+		Processes: processes,
+	}
+
+	return NewMultiProcessWithOptions(ctx, opts)
+}
+
+// NewMultiProcessWithOptions returns a new instance of `MultiProcess` configured by 'opts'.
+func NewMultiProcessWithOptions(ctx context.Context, opts *MultiProcessOptions) (Process, error) {
+
+	if opts.Cache != nil && len(opts.URIs) != len(opts.Processes) {
+		return nil, fmt.Errorf("Caching requires a URI for each process")
+	}
+
 	p := &MultiProcess{
-		processes: processes,
+		processes: opts.Processes,
+		uris:      opts.URIs,
+		cache:     opts.Cache,
 	}
 
 	return p, nil
 }
 
 // Tranform applies the `Tranform` method for all its internal `Process` instances. All processes must succeed
-// in order for this method to succeed.
+// in order for this method to succeed. If 'p' was created with a cache bucket and the same image has previously
+// been transformed by the same processes then a copy of the cached result is written to 'target_bucket' instead.
 func (p *MultiProcess) Transform(ctx context.Context, source_bucket bucket.Bucket, target_bucket bucket.Bucket, path string) (string, error) {
 
+	if p.cache == nil {
+		return p.transform(ctx, source_bucket, target_bucket, path)
+	}
+
+	logger := slog.Default()
+	logger = logger.With("path", path)
+
+	key, err := CacheKey(ctx, source_bucket, path, p.uris)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to derive cache key for %s, %w", path, err)
+	}
+
+	logger = logger.With("key", key)
+
+	cached_path, err := fromCache(ctx, p.cache, target_bucket, key)
+
+	if err != nil {
+		logger.Warn("Failed to read processed image from cache", "error", err)
+	} else if cached_path != "" {
+		logger.Debug("Use cached processed image", "cached_path", cached_path)
+		return cached_path, nil
+	}
+
+	final_path, err := p.transform(ctx, source_bucket, target_bucket, path)
+
+	if err != nil {
+		return "", err
+	}
+
+	if final_path != "" && final_path != path {
+
+		err := toCache(ctx, target_bucket, p.cache, final_path, key)
+
+		if err != nil {
+			logger.Warn("Failed to write processed image to cache", "error", err)
+		}
+	}
+
+	return final_path, nil
+}
+
+// transform applies the `Tranform` method for all its internal `Process` instances.
+func (p *MultiProcess) transform(ctx context.Context, source_bucket bucket.Bucket, target_bucket bucket.Bucket, path string) (string, error) {
+
 	final_path := path
 
 	for _, current_p := range p.processes {
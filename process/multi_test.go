@@ -0,0 +1,99 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"testing"
+
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/tempfile"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+// countingProcess implements the `Process` interface and records the number of times it has been invoked.
+type countingProcess struct {
+	Process
+	count int
+}
+
+func (p *countingProcess) Transform(ctx context.Context, source_bucket bucket.Bucket, target_bucket bucket.Bucket, path string) (string, error) {
+
+	p.count += 1
+
+	im := image.NewGray(image.Rect(0, 0, 4, 4))
+	tmpfile, _, err := tempfile.TempFileWithImage(ctx, target_bucket, im)
+	return tmpfile, err
+}
+
+func TestMultiProcessCache(t *testing.T) {
+
+	ctx := context.Background()
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to register gocloud buckets, %v", err)
+	}
+
+	source_bucket, err := bucket.NewBucket(ctx, fmt.Sprintf("file://%s?metadata=skip", t.TempDir()))
+
+	if err != nil {
+		t.Fatalf("Failed to create source bucket, %v", err)
+	}
+
+	target_bucket, err := bucket.NewBucket(ctx, fmt.Sprintf("file://%s?metadata=skip", t.TempDir()))
+
+	if err != nil {
+		t.Fatalf("Failed to create target bucket, %v", err)
+	}
+
+	cache_bucket, err := bucket.NewBucket(ctx, fmt.Sprintf("file://%s?metadata=skip", t.TempDir()))
+
+	if err != nil {
+		t.Fatalf("Failed to create cache bucket, %v", err)
+	}
+
+	wr, err := source_bucket.NewWriter(ctx, "test.txt", nil)
+
+	if err != nil {
+		t.Fatalf("Failed to create writer, %v", err)
+	}
+
+	io.WriteString(wr, "hello world")
+	wr.Close()
+
+	pr := &countingProcess{}
+
+	opts := &MultiProcessOptions{
+		Processes: []Process{pr},
+		URIs:      []string{"counting://"},
+		Cache:     cache_bucket,
+	}
+
+	p, err := NewMultiProcessWithOptions(ctx, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create multi process, %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+
+		path, err := p.Transform(ctx, source_bucket, target_bucket, "test.txt")
+
+		if err != nil {
+			t.Fatalf("Failed to transform image, %v", err)
+		}
+
+		_, err = target_bucket.Attributes(ctx, path)
+
+		if err != nil {
+			t.Fatalf("Failed to derive attributes for %s, %v", path, err)
+		}
+	}
+
+	if pr.count != 1 {
+		t.Fatalf("Expected process to be invoked once, but was invoked %d times", pr.count)
+	}
+}
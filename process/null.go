@@ -6,6 +6,7 @@ import (
 	"net/url"
 
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 )
 
 func init() {
@@ -16,6 +17,19 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.PROCESS, &handler.Description{
+		Scheme:  "null",
+		Summary: "Leave images unchanged.",
+		Syntax:  "null://",
+		Examples: []string{
+			"null://",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // type NullProcess implements the `Process` interface but does not apply any transformations to an image.
@@ -9,6 +9,7 @@ import (
 
 	"github.com/aaronland/go-image/v2/decode"
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 	"github.com/aaronland/go-picturebook/tempfile"
 )
 
@@ -20,6 +21,19 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.PROCESS, &handler.Description{
+		Scheme:  "rotate",
+		Summary: "Rotate JPEG images based on their EXIF Orientation property.",
+		Syntax:  "rotate://",
+		Examples: []string{
+			"rotate://",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // type RotateProcess implements the `Process` interface and rotates and image based on its EXIF `Orientation` property.
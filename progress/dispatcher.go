@@ -0,0 +1,86 @@
+package progress
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// type Dispatcher delivers events, and requests to clear, to a `Monitor` from a single goroutine in the order they were received.
+// This allows events to be signaled from multiple goroutines without monitors needing to be safe for concurrent use and without
+// events being delivered out of order.
+type Dispatcher struct {
+	monitor Monitor
+	queue   chan func()
+	wg      *sync.WaitGroup
+	once    *sync.Once
+}
+
+// NewDispatcher returns a new `Dispatcher` instance delivering events to 'm'. If 'm' is nil events are discarded. The
+// `Close` method must be called to wait for all the events to be delivered and to stop the dispatcher's goroutine.
+func NewDispatcher(ctx context.Context, m Monitor) *Dispatcher {
+
+	d := &Dispatcher{
+		monitor: m,
+		queue:   make(chan func(), 64),
+		wg:      new(sync.WaitGroup),
+		once:    new(sync.Once),
+	}
+
+	d.wg.Add(1)
+
+	go func() {
+
+		defer d.wg.Done()
+
+		for fn := range d.queue {
+			fn()
+		}
+	}()
+
+	return d
+}
+
+// Signal queues 'ev' to be delivered to the dispatcher's monitor.
+func (d *Dispatcher) Signal(ctx context.Context, ev *Event) {
+
+	if d.monitor == nil {
+		return
+	}
+
+	d.queue <- func() {
+
+		err := d.monitor.Signal(ctx, ev)
+
+		if err != nil {
+			slog.Debug("Failed to signal progress event", "stage", ev.Stage, "error", err)
+		}
+	}
+}
+
+// Clear queues a request to clear the dispatcher's monitor once all the events queued before it have been delivered.
+func (d *Dispatcher) Clear() {
+
+	if d.monitor == nil {
+		return
+	}
+
+	d.queue <- func() {
+
+		err := d.monitor.Clear()
+
+		if err != nil {
+			slog.Warn("Failed to clear progress monitor", "error", err)
+		}
+	}
+}
+
+// Close waits for all the queued events to be delivered and stops the dispatcher. It does not close the dispatcher's monitor.
+func (d *Dispatcher) Close() {
+
+	d.once.Do(func() {
+		close(d.queue)
+	})
+
+	d.wg.Wait()
+}
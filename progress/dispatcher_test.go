@@ -0,0 +1,83 @@
+package progress
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// recordingMonitor implements the `Monitor` interface recording each event and the number of events delivered before each call to Clear.
+type recordingMonitor struct {
+	Monitor
+	events []*Event
+	clears []int
+}
+
+func (m *recordingMonitor) Signal(ctx context.Context, ev *Event) error {
+	m.events = append(m.events, ev)
+	return nil
+}
+
+func (m *recordingMonitor) Clear() error {
+	m.clears = append(m.clears, len(m.events))
+	return nil
+}
+
+func (m *recordingMonitor) Close() error {
+	return nil
+}
+
+func TestDispatcher(t *testing.T) {
+
+	ctx := context.Background()
+
+	m := &recordingMonitor{}
+	d := NewDispatcher(ctx, m)
+
+	tracker := NewTracker(STAGE_PROCESS, 100)
+	wg := new(sync.WaitGroup)
+
+	for i := 0; i < 100; i++ {
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			d.Signal(ctx, tracker.Next(""))
+		}()
+	}
+
+	wg.Wait()
+
+	d.Clear()
+	d.Close()
+
+	if len(m.events) != 100 {
+		t.Fatalf("Unexpected number of events: %d", len(m.events))
+	}
+
+	// Events are numbered by the tracker and then signaled so events from concurrent goroutines may arrive out of order
+	// but every event must be delivered, exactly once, before the monitor is cleared
+
+	seen := make(map[int]bool)
+
+	for _, ev := range m.events {
+
+		if ev.Stage != STAGE_PROCESS || ev.Pages != 100 || seen[ev.Page] {
+			t.Fatalf("Unexpected event: %v", ev)
+		}
+
+		seen[ev.Page] = true
+	}
+
+	if len(m.clears) != 1 || m.clears[0] != 100 {
+		t.Fatalf("Expected monitor to be cleared after all events were delivered: %v", m.clears)
+	}
+
+	// A dispatcher without a monitor discards events
+
+	d = NewDispatcher(ctx, nil)
+	d.Signal(ctx, NewEvent(1, 1))
+	d.Clear()
+	d.Close()
+}
@@ -1,13 +1,27 @@
 package progress
 
-// Event defines details about a (picturebook) page being processed.
+import (
+	"time"
+)
+
+// Event defines details about the progress of one stage of creating a picturebook.
 type Event struct {
-	// The total number of pages being processed
-	Pages int
-	// The current page being processed
-	Page int
+	// The total number of items (for example files or pages) in the current stage, or `UNKNOWN_COUNT` if it is not known yet
+	Pages int `json:"pages"`
+	// The number of items completed in the current stage
+	Page int `json:"page"`
 	// An optional string describing the event
-	Message string
+	Message string `json:"message,omitempty"`
+	// The stage of creating a picturebook the event belongs to. One of the STAGE_* constants.
+	Stage string `json:"stage,omitempty"`
+	// The time the event was created
+	Time time.Time `json:"time"`
+	// The path of the file currently being processed, if any
+	Path string `json:"path,omitempty"`
+	// The time elapsed since the current stage started. This is encoded as nanoseconds in JSON.
+	Elapsed time.Duration `json:"elapsed"`
+	// The estimated time remaining until the current stage is complete, if known. This is encoded as nanoseconds in JSON.
+	ETA time.Duration `json:"eta,omitempty"`
 }
 
 // NewEvent returns a new `Event` instance derived from 'page' and 'count'.
@@ -15,5 +29,18 @@ func NewEvent(page int, count int) *Event {
 	return &Event{
 		Page:  page,
 		Pages: count,
+		Time:  time.Now(),
+	}
+}
+
+// EstimateETA returns the estimated time remaining to process 'count' items given that 'done' items have been processed since 't1'.
+// It returns 0 if an estimate can not be made.
+func EstimateETA(t1 time.Time, done int, count int) time.Duration {
+
+	if done <= 0 || count <= done {
+		return 0
 	}
+
+	per_item := time.Since(t1) / time.Duration(done)
+	return per_item * time.Duration(count-done)
 }
@@ -17,6 +17,25 @@ type Monitor interface {
 	Close() error
 }
 
+// Finisher is an optional interface for `Monitor` instances which report the outcome of a picturebook creation process.
+type Finisher interface {
+	// Finish() reports that the picturebook creation process has finished, successfully if the error is nil or otherwise failed.
+	Finish(context.Context, error) error
+}
+
+// Finish reports that the picturebook creation process being monitored by 'm' has finished, successfully if 'err' is nil or
+// otherwise failed. If 'm' does not implement the `Finisher` interface this method does nothing.
+func Finish(ctx context.Context, m Monitor, err error) error {
+
+	f, ok := m.(Finisher)
+
+	if !ok {
+		return nil
+	}
+
+	return f.Finish(ctx, err)
+}
+
 // type MonitorInitializeFunc defined a common initialization function for instances implementing the Monitor interface.
 // This is specified when the packages definining those instances call `RegisterMonitor` and invoked with the `NewMonitor`
 // method is called.
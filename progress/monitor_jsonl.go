@@ -0,0 +1,118 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/aaronland/go-picturebook/handler"
+)
+
+// JSONLMonitor implements the `Monitor` interface for writing progress reports as JSON Lines, one JSON-encoded `Event` per line.
+type JSONLMonitor struct {
+	Monitor
+	encoder *json.Encoder
+	closer  io.Closer
+	mu      *sync.Mutex
+}
+
+func init() {
+
+	ctx := context.Background()
+	err := RegisterMonitor(ctx, "jsonl", NewJSONLMonitor)
+
+	if err != nil {
+		panic(err)
+	}
+
+	err = handler.RegisterDescription(ctx, handler.PROGRESS, &handler.Description{
+		Scheme:  "jsonl",
+		Summary: "Report progress as JSON Lines, one JSON-encoded event per line, written to STDERR or appended to a file.",
+		Syntax:  "jsonl://{PATH}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "path",
+				Location:    handler.PARAMETER_PATH,
+				Type:        "path",
+				Description: "The absolute path to a file on the local disk that events are appended to. If empty events are written to STDERR.",
+			},
+		},
+		Examples: []string{
+			"jsonl://",
+			"jsonl:///path/to/progress.jsonl",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewJSONLMonitor returns a new `JSONLMonitor` instance implementing the `Monitor` interface configured by 'uri' which is
+// expected to take the form of:
+//
+//	jsonl://{PATH}
+//
+// Where {PATH} is an optional path to a file that events are appended to. If empty events are written to STDERR.
+func NewJSONLMonitor(ctx context.Context, uri string) (Monitor, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	var wr io.Writer = os.Stderr
+	var closer io.Closer
+
+	if u.Path != "" {
+
+		fh, err := os.OpenFile(u.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to open %s for writing, %w", u.Path, err)
+		}
+
+		wr = fh
+		closer = fh
+	}
+
+	enc := json.NewEncoder(wr)
+	enc.SetEscapeHTML(false)
+
+	m := &JSONLMonitor{
+		encoder: enc,
+		closer:  closer,
+		mu:      new(sync.Mutex),
+	}
+
+	return m, nil
+}
+
+// Signal writes 'ev' as a single line of JSON.
+func (m *JSONLMonitor) Signal(ctx context.Context, ev *Event) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.encoder.Encode(ev)
+}
+
+// Clear doesn't do anything.
+func (m *JSONLMonitor) Clear() error {
+	return nil
+}
+
+// Close closes the file that events are written to, if any.
+func (m *JSONLMonitor) Close() error {
+
+	if m.closer == nil {
+		return nil
+	}
+
+	return m.closer.Close()
+}
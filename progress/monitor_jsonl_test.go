@@ -0,0 +1,75 @@
+package progress
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONLMonitor(t *testing.T) {
+
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "progress.jsonl")
+
+	m, err := NewMonitor(ctx, fmt.Sprintf("jsonl://%s", path))
+
+	if err != nil {
+		t.Fatalf("Failed to create monitor, %v", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+
+		ev := NewEvent(i, 3)
+		ev.Stage = STAGE_RENDER
+		ev.Path = fmt.Sprintf("%d.jpg", i)
+
+		err := m.Signal(ctx, ev)
+
+		if err != nil {
+			t.Fatalf("Failed to signal event, %v", err)
+		}
+	}
+
+	err = m.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close monitor, %v", err)
+	}
+
+	fh, err := os.Open(path)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", path, err)
+	}
+
+	defer fh.Close()
+
+	scanner := bufio.NewScanner(fh)
+	count := 0
+
+	for scanner.Scan() {
+
+		count += 1
+
+		var ev Event
+
+		err := json.Unmarshal(scanner.Bytes(), &ev)
+
+		if err != nil {
+			t.Fatalf("Failed to decode line %d, %v", count, err)
+		}
+
+		if ev.Page != count || ev.Pages != 3 || ev.Stage != STAGE_RENDER || ev.Path != fmt.Sprintf("%d.jpg", count) || ev.Time.IsZero() {
+			t.Fatalf("Unexpected event on line %d: %s", count, scanner.Text())
+		}
+	}
+
+	if count != 3 {
+		t.Fatalf("Unexpected number of events: %d", count)
+	}
+}
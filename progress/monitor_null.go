@@ -2,6 +2,8 @@ package progress
 
 import (
 	"context"
+
+	"github.com/aaronland/go-picturebook/handler"
 )
 
 // NullMonitor implements the `Monitor` interface for receiving progress reports but not doing anything with them.
@@ -17,6 +19,19 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.PROGRESS, &handler.Description{
+		Scheme:  "null",
+		Summary: "Discard progress reports.",
+		Syntax:  "null://",
+		Examples: []string{
+			"null://",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // NewNullMonitor returns a new `NullMonitor` instance implementing the `Monitor` interface.
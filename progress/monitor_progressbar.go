@@ -3,6 +3,7 @@ package progress
 import (
 	"context"
 
+	"github.com/aaronland/go-picturebook/handler"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -20,6 +21,19 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.PROGRESS, &handler.Description{
+		Scheme:  "progressbar",
+		Summary: "Report progress using a progress bar written to the terminal.",
+		Syntax:  "progressbar://",
+		Examples: []string{
+			"progressbar://",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // NewProgressBarMonitor returns a new `ProgressBarMonitor` instance implementing the `Monitor` interface.
@@ -35,7 +49,13 @@ func (m *ProgressBarMonitor) Signal(ctx context.Context, ev *Event) error {
 		m.progressbar = progressbar.Default(int64(ev.Pages))
 	}
 
-	m.progressbar.Describe(ev.Message)
+	desc := ev.Message
+
+	if desc == "" {
+		desc = ev.Stage
+	}
+
+	m.progressbar.Describe(desc)
 	m.progressbar.ChangeMax(ev.Pages)
 	m.progressbar.Set(ev.Page)
 	return nil
@@ -0,0 +1,405 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aaronland/go-picturebook/handler"
+)
+
+// WEBHOOK_STATUS_RUNNING is the status of webhook payloads sent while a picturebook is being created.
+const WEBHOOK_STATUS_RUNNING string = "running"
+
+// WEBHOOK_STATUS_COMPLETED is the status of the final webhook payload sent when a picturebook was created successfully.
+const WEBHOOK_STATUS_COMPLETED string = "completed"
+
+// WEBHOOK_STATUS_FAILED is the status of the final webhook payload sent when a picturebook could not be created.
+const WEBHOOK_STATUS_FAILED string = "failed"
+
+// type WebhookPayload defines the JSON-encoded body of the requests sent by `WebhookMonitor`.
+type WebhookPayload struct {
+	// The status of the picturebook creation process. One of the WEBHOOK_STATUS_* constants.
+	Status string `json:"status"`
+	// The error which caused the picturebook creation process to fail, if any.
+	Error string `json:"error,omitempty"`
+	// The events signaled since the previous payload was sent, in the order they were signaled.
+	Events []*Event `json:"events"`
+}
+
+// WebhookMonitor implements the `Monitor` and `Finisher` interfaces for sending progress reports to an HTTP endpoint.
+// Events are buffered and sent, in batches, as JSON-encoded `WebhookPayload` POST requests at regular intervals. A final
+// payload, with any remaining events, is sent with a "completed" or "failed" status when the `Finish` method is called.
+type WebhookMonitor struct {
+	Monitor
+	url      string
+	client   *http.Client
+	retries  int
+	backoff  time.Duration
+	events   []*Event
+	finished bool
+	mu       *sync.Mutex
+	send_mu  *sync.Mutex
+	cancel   context.CancelFunc
+	wg       *sync.WaitGroup
+	once     *sync.Once
+}
+
+func init() {
+
+	ctx := context.Background()
+	err := RegisterMonitor(ctx, "webhook", NewWebhookMonitor)
+
+	if err != nil {
+		panic(err)
+	}
+
+	err = handler.RegisterDescription(ctx, handler.PROGRESS, &handler.Description{
+		Scheme:  "webhook",
+		Summary: "Report progress by POST-ing batches of JSON-encoded events, and a final completion or failure event, to an HTTP endpoint.",
+		Syntax:  "webhook://?url={URL}&every={DURATION}&retries={INTEGER}&backoff={DURATION}&timeout={DURATION}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "url",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "url",
+				Description: "The http:// or https:// URL that events are sent to.",
+				Required:    true,
+			},
+			{
+				Name:        "every",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "duration",
+				Description: "How often batches of events are sent.",
+				Default:     "5s",
+			},
+			{
+				Name:        "retries",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "integer",
+				Description: "The number of times to retry a request which failed because of a network error, a 429 or a 5XX response.",
+				Default:     "3",
+			},
+			{
+				Name:        "backoff",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "duration",
+				Description: "How long to wait before the first retry. The wait is doubled for each subsequent retry.",
+				Default:     "1s",
+			},
+			{
+				Name:        "timeout",
+				Location:    handler.PARAMETER_QUERY,
+				Type:        "duration",
+				Description: "The maximum time to wait for each request to complete.",
+				Default:     "10s",
+			},
+		},
+		Examples: []string{
+			"webhook://?url=http://localhost:8080/progress",
+			"webhook://?url=http://localhost:8080/progress&every=30s&retries=5",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewWebhookMonitor returns a new `WebhookMonitor` instance implementing the `Monitor` interface configured by 'uri' which is
+// expected to take the form of:
+//
+//	webhook://?{PARAMETERS}
+//
+// Where valid parameters are:
+// * `url` The http:// or https:// URL that events are sent to. Required.
+// * `every` How often batches of events are sent. Default is 5s.
+// * `retries` The number of times to retry a request which failed because of a network error, a 429 or a 5XX response. Default is 3.
+// * `backoff` How long to wait before the first retry. The wait is doubled for each subsequent retry. Default is 1s.
+// * `timeout` The maximum time to wait for each request to complete. Default is 10s.
+func NewWebhookMonitor(ctx context.Context, uri string) (Monitor, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	webhook_url := q.Get("url")
+
+	if webhook_url == "" {
+		return nil, fmt.Errorf("Missing ?url= parameter")
+	}
+
+	webhook_u, err := url.Parse(webhook_url)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse ?url= parameter, %w", err)
+	}
+
+	if webhook_u.Scheme != "http" && webhook_u.Scheme != "https" {
+		return nil, fmt.Errorf("Invalid ?url= parameter, scheme must be http or https")
+	}
+
+	every := 5 * time.Second
+	retries := 3
+	backoff := 1 * time.Second
+	timeout := 10 * time.Second
+
+	durations := map[string]*time.Duration{
+		"every":   &every,
+		"backoff": &backoff,
+		"timeout": &timeout,
+	}
+
+	for k, d := range durations {
+
+		str_d := q.Get(k)
+
+		if str_d == "" {
+			continue
+		}
+
+		v, err := time.ParseDuration(str_d)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse ?%s= parameter, %w", k, err)
+		}
+
+		if v <= 0 {
+			return nil, fmt.Errorf("Invalid ?%s= parameter, must be greater than 0", k)
+		}
+
+		*d = v
+	}
+
+	str_retries := q.Get("retries")
+
+	if str_retries != "" {
+
+		v, err := strconv.Atoi(str_retries)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse ?retries= parameter, %w", err)
+		}
+
+		if v < 0 {
+			return nil, fmt.Errorf("Invalid ?retries= parameter, must not be negative")
+		}
+
+		retries = v
+	}
+
+	flush_ctx, cancel := context.WithCancel(context.Background())
+
+	m := &WebhookMonitor{
+		url:     webhook_url,
+		client:  &http.Client{Timeout: timeout},
+		retries: retries,
+		backoff: backoff,
+		events:  make([]*Event, 0),
+		mu:      new(sync.Mutex),
+		send_mu: new(sync.Mutex),
+		cancel:  cancel,
+		wg:      new(sync.WaitGroup),
+		once:    new(sync.Once),
+	}
+
+	m.wg.Add(1)
+
+	go func() {
+
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-flush_ctx.Done():
+				return
+			case <-ticker.C:
+
+				// Progress reports are best-effort so a batch which can not be sent is logged and discarded
+
+				err := m.flush(flush_ctx, WEBHOOK_STATUS_RUNNING, nil)
+
+				if err != nil {
+					slog.Warn("Failed to send progress events to webhook", "error", err)
+				}
+			}
+		}
+	}()
+
+	return m, nil
+}
+
+// Signal adds 'ev' to the batch of events to be sent to the webhook. Events signaled after the `Finish` method has been called are discarded.
+func (m *WebhookMonitor) Signal(ctx context.Context, ev *Event) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.finished {
+		m.events = append(m.events, ev)
+	}
+
+	return nil
+}
+
+// Clear doesn't do anything.
+func (m *WebhookMonitor) Clear() error {
+	return nil
+}
+
+// Finish sends any remaining events to the webhook with a "completed" status, if 'run_err' is nil, or a "failed" status and
+// the error message otherwise. The final payload is sent even if 'ctx' has been cancelled. Subsequent calls do nothing.
+func (m *WebhookMonitor) Finish(ctx context.Context, run_err error) error {
+
+	m.mu.Lock()
+
+	if m.finished {
+		m.mu.Unlock()
+		return nil
+	}
+
+	m.finished = true
+	m.mu.Unlock()
+
+	m.stop()
+
+	status := WEBHOOK_STATUS_COMPLETED
+
+	if run_err != nil {
+		status = WEBHOOK_STATUS_FAILED
+	}
+
+	return m.flush(context.WithoutCancel(ctx), status, run_err)
+}
+
+// Close stops sending batches of events to the webhook. If the `Finish` method has not been called any remaining events
+// are sent with a "running" status.
+func (m *WebhookMonitor) Close() error {
+
+	m.stop()
+
+	m.mu.Lock()
+	finished := m.finished
+	m.finished = true
+	m.mu.Unlock()
+
+	if finished {
+		return nil
+	}
+
+	return m.flush(context.Background(), WEBHOOK_STATUS_RUNNING, nil)
+}
+
+// stop stops the goroutine sending batches of events at regular intervals and waits for it to exit.
+func (m *WebhookMonitor) stop() {
+
+	m.once.Do(func() {
+		m.cancel()
+	})
+
+	m.wg.Wait()
+}
+
+// flush sends the events signaled since the previous payload was sent to the webhook with 'status'. Payloads with a
+// "running" status are only sent if there are events to send.
+func (m *WebhookMonitor) flush(ctx context.Context, status string, run_err error) error {
+
+	m.send_mu.Lock()
+	defer m.send_mu.Unlock()
+
+	m.mu.Lock()
+	events := m.events
+	m.events = make([]*Event, 0)
+	m.mu.Unlock()
+
+	if status == WEBHOOK_STATUS_RUNNING && len(events) == 0 {
+		return nil
+	}
+
+	payload := &WebhookPayload{
+		Status: status,
+		Events: events,
+	}
+
+	if run_err != nil {
+		payload.Error = run_err.Error()
+	}
+
+	body, err := json.Marshal(payload)
+
+	if err != nil {
+		return fmt.Errorf("Failed to marshal webhook payload, %w", err)
+	}
+
+	delay := m.backoff
+
+	for attempt := 0; ; attempt++ {
+
+		retry, err := m.post(ctx, body)
+
+		if err == nil {
+			return nil
+		}
+
+		if !retry || attempt >= m.retries {
+			return fmt.Errorf("Failed to send webhook payload after %d attempt(s), %w", attempt+1, err)
+		}
+
+		slog.Debug("Retry webhook payload", "status", status, "attempt", attempt+1, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+			// pass
+		}
+
+		delay = delay * 2
+	}
+}
+
+// post sends 'body' to the webhook and returns a boolean value signaling whether a failed request should be retried.
+func (m *WebhookMonitor) post(ctx context.Context, body []byte) (bool, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, bytes.NewReader(body))
+
+	if err != nil {
+		return false, fmt.Errorf("Failed to create request, %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := m.client.Do(req)
+
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("Failed to execute request, %w", err)
+	}
+
+	defer rsp.Body.Close()
+
+	io.Copy(io.Discard, rsp.Body)
+
+	switch {
+	case rsp.StatusCode >= 200 && rsp.StatusCode < 300:
+		return false, nil
+	case rsp.StatusCode == http.StatusTooManyRequests || rsp.StatusCode >= 500:
+		return true, fmt.Errorf("Webhook returned %s", rsp.Status)
+	default:
+		return false, fmt.Errorf("Webhook returned %s", rsp.Status)
+	}
+}
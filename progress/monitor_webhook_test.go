@@ -0,0 +1,196 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookRecorder records the payloads sent to an `httptest.Server` failing the first 'failures' requests with a 503 response.
+type webhookRecorder struct {
+	failures int
+	requests int
+	payloads []*WebhookPayload
+	mu       *sync.Mutex
+}
+
+func (rec *webhookRecorder) ServeHTTP(rsp http.ResponseWriter, req *http.Request) {
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.requests += 1
+
+	if rec.requests <= rec.failures {
+		http.Error(rsp, "Unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var p *WebhookPayload
+
+	err := json.NewDecoder(req.Body).Decode(&p)
+
+	if err != nil {
+		http.Error(rsp, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rec.payloads = append(rec.payloads, p)
+}
+
+func TestWebhookMonitor(t *testing.T) {
+
+	ctx := context.Background()
+
+	rec := &webhookRecorder{mu: new(sync.Mutex)}
+	s := httptest.NewServer(rec)
+	defer s.Close()
+
+	m, err := NewMonitor(ctx, fmt.Sprintf("webhook://?url=%s&every=10ms", s.URL))
+
+	if err != nil {
+		t.Fatalf("Failed to create monitor, %v", err)
+	}
+
+	tracker := NewTracker(STAGE_RENDER, 5)
+
+	for i := 0; i < 5; i++ {
+
+		err := m.Signal(ctx, tracker.Next(fmt.Sprintf("%d.jpg", i)))
+
+		if err != nil {
+			t.Fatalf("Failed to signal event, %v", err)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	err = Finish(ctx, m, nil)
+
+	if err != nil {
+		t.Fatalf("Failed to finish monitor, %v", err)
+	}
+
+	err = m.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close monitor, %v", err)
+	}
+
+	if len(rec.payloads) < 2 {
+		t.Fatalf("Expected events to be sent in more than one batch, got %d payloads", len(rec.payloads))
+	}
+
+	page := 0
+
+	for i, p := range rec.payloads {
+
+		last := i == len(rec.payloads)-1
+
+		switch {
+		case last && p.Status != WEBHOOK_STATUS_COMPLETED:
+			t.Fatalf("Unexpected status for final payload: %s", p.Status)
+		case !last && p.Status != WEBHOOK_STATUS_RUNNING:
+			t.Fatalf("Unexpected status for payload %d: %s", i, p.Status)
+		}
+
+		for _, ev := range p.Events {
+
+			page += 1
+
+			if ev.Page != page {
+				t.Fatalf("Expected event for page %d, got %d", page, ev.Page)
+			}
+		}
+	}
+
+	if page != 5 {
+		t.Fatalf("Expected 5 events, got %d", page)
+	}
+}
+
+func TestWebhookMonitorFailure(t *testing.T) {
+
+	ctx := context.Background()
+
+	rec := &webhookRecorder{failures: 2, mu: new(sync.Mutex)}
+	s := httptest.NewServer(rec)
+	defer s.Close()
+
+	m, err := NewMonitor(ctx, fmt.Sprintf("webhook://?url=%s&every=1h&retries=2&backoff=1ms", s.URL))
+
+	if err != nil {
+		t.Fatalf("Failed to create monitor, %v", err)
+	}
+
+	defer m.Close()
+
+	m.Signal(ctx, NewEvent(1, 2))
+
+	err = Finish(ctx, m, fmt.Errorf("Boom"))
+
+	if err != nil {
+		t.Fatalf("Failed to finish monitor, %v", err)
+	}
+
+	if rec.requests != 3 || len(rec.payloads) != 1 {
+		t.Fatalf("Expected final payload to be sent after 2 retries, got %d requests", rec.requests)
+	}
+
+	p := rec.payloads[0]
+
+	if p.Status != WEBHOOK_STATUS_FAILED || p.Error != "Boom" || len(p.Events) != 1 {
+		t.Fatalf("Unexpected final payload: %v", p)
+	}
+
+	// Retries are exhausted
+
+	rec = &webhookRecorder{failures: 10, mu: new(sync.Mutex)}
+	s2 := httptest.NewServer(rec)
+	defer s2.Close()
+
+	m2, err := NewMonitor(ctx, fmt.Sprintf("webhook://?url=%s&every=1h&retries=1&backoff=1ms", s2.URL))
+
+	if err != nil {
+		t.Fatalf("Failed to create monitor, %v", err)
+	}
+
+	defer m2.Close()
+
+	err = Finish(ctx, m2, nil)
+
+	if err == nil {
+		t.Fatalf("Expected final payload to fail")
+	}
+
+	if rec.requests != 2 {
+		t.Fatalf("Expected 2 requests, got %d", rec.requests)
+	}
+}
+
+func TestNewWebhookMonitorInvalid(t *testing.T) {
+
+	ctx := context.Background()
+
+	uris := []string{
+		"webhook://",
+		"webhook://?url=ftp://example.com",
+		"webhook://?url=http://example.com&every=soon",
+		"webhook://?url=http://example.com&every=0s",
+		"webhook://?url=http://example.com&retries=-1",
+	}
+
+	for _, uri := range uris {
+
+		_, err := NewMonitor(ctx, uri)
+
+		if err == nil {
+			t.Fatalf("Expected %s to fail", uri)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package progress
+
+// STAGE_GATHER is the stage of creating a picturebook in which the files in its sources are gathered.
+const STAGE_GATHER string = "gather"
+
+// STAGE_PROCESS is the stage of creating a picturebook in which gathered images are filtered, captioned and processed.
+const STAGE_PROCESS string = "process"
+
+// STAGE_SORT is the stage of creating a picturebook in which images are sorted.
+const STAGE_SORT string = "sort"
+
+// STAGE_RENDER is the stage of creating a picturebook in which images and text are added to pages.
+const STAGE_RENDER string = "render"
+
+// STAGE_SAVE is the stage of creating a picturebook in which the finished picturebook is written.
+const STAGE_SAVE string = "save"
+
+// Stages is the list of stages of creating a picturebook, in the order they happen.
+var Stages = []string{
+	STAGE_GATHER,
+	STAGE_PROCESS,
+	STAGE_SORT,
+	STAGE_RENDER,
+	STAGE_SAVE,
+}
@@ -0,0 +1,69 @@
+package progress
+
+import (
+	"sync"
+	"time"
+)
+
+// UNKNOWN_COUNT is the value of `Event.Pages` used to signal that the number of items in a stage is not known yet, for example
+// while files are still being gathered.
+const UNKNOWN_COUNT int = -1
+
+// type Tracker tracks progress through a single stage of creating a picturebook and derives events, with elapsed times
+// and estimated times remaining, for each item completed in that stage. It is safe for concurrent use.
+type Tracker struct {
+	stage   string
+	count   int
+	done    int
+	started time.Time
+	mu      *sync.Mutex
+}
+
+// NewTracker returns a new `Tracker` instance for 'stage', one of the STAGE_* constants, which has 'count' items. If the
+// number of items is not known 'count' should be `UNKNOWN_COUNT`.
+func NewTracker(stage string, count int) *Tracker {
+
+	t := &Tracker{
+		stage:   stage,
+		count:   count,
+		started: time.Now(),
+		mu:      new(sync.Mutex),
+	}
+
+	return t
+}
+
+// Event returns a new `Event` describing the current progress through the stage, and the item at 'path', without
+// marking any items as completed.
+func (t *Tracker) Event(path string) *Event {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.event(path)
+}
+
+// Next marks the item at 'path' as completed and returns a new `Event` describing the progress through the stage.
+func (t *Tracker) Next(path string) *Event {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.done += 1
+	return t.event(path)
+}
+
+// event returns a new `Event` describing the current progress through the stage. It is expected to be called while holding `t.mu`.
+func (t *Tracker) event(path string) *Event {
+
+	ev := NewEvent(t.done, t.count)
+	ev.Stage = t.stage
+	ev.Path = path
+	ev.Elapsed = time.Since(t.started)
+
+	if t.count != UNKNOWN_COUNT {
+		ev.ETA = EstimateETA(t.started, t.done, t.count)
+	}
+
+	return ev
+}
@@ -0,0 +1,158 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"codeberg.org/go-pdf/fpdf"
+	"github.com/aaronland/go-picturebook/picture"
+)
+
+// type FPDFRenderer implements the `Renderer` and `layout.TextMeasurer` interfaces for drawing pages in a PDF document using the fpdf package.
+type FPDFRenderer struct {
+	Renderer
+	// The PDF document to draw pages in. Its unit of measurement is expected to be inches.
+	PDF *fpdf.Fpdf
+	// The resolution, in dots per inch, assigned to images.
+	DPI float64
+}
+
+// NewFPDFRenderer returns a new `FPDFRenderer` instance for drawing pages in 'pdf'.
+func NewFPDFRenderer(pdf *fpdf.Fpdf, dpi float64) *FPDFRenderer {
+
+	r := &FPDFRenderer{
+		PDF: pdf,
+		DPI: dpi,
+	}
+
+	return r
+}
+
+// AddPage draws 'page' as a new page in the renderer's PDF document.
+func (r *FPDFRenderer) AddPage(ctx context.Context, page *picture.PictureBookPage) error {
+
+	switch page.Type {
+	case picture.PAGE_TYPE_PICTURE:
+		return r.addPicture(ctx, page)
+	default:
+		r.PDF.AddPage()
+		r.drawLines(page.Lines)
+		return nil
+	}
+}
+
+// StringWidth returns the width, in inches, of 'txt' drawn in the PDF document's current font at font size 'size'.
+func (r *FPDFRenderer) StringWidth(txt string, size float64) float64 {
+
+	font_sz, _ := r.PDF.GetFontSize()
+
+	if size == font_sz {
+		return r.PDF.GetStringWidth(txt)
+	}
+
+	r.PDF.SetFontSize(size)
+	defer r.PDF.SetFontSize(font_sz)
+
+	return r.PDF.GetStringWidth(txt)
+}
+
+func (r *FPDFRenderer) addPicture(ctx context.Context, page *picture.PictureBookPage) error {
+
+	if page.Bucket == nil {
+		return fmt.Errorf("Page for %s is missing an image bucket", page.Path)
+	}
+
+	im_r, err := page.Bucket.NewReader(ctx, page.Image, nil)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create new reader (info) for %s, %v", page.Image, err)
+	}
+
+	defer im_r.Close()
+
+	// https://godoc.org/github.com/jung-kurt/fpdf#ImageOptions
+
+	image_opts := fpdf.ImageOptions{
+		ReadDpi:   false,
+		ImageType: page.Format,
+	}
+
+	info, err := r.registerImage(page.Image, image_opts, im_r)
+
+	if err != nil {
+		return fmt.Errorf("Failed to register image %s with format (%s), %w", page.Image, page.Format, err)
+	}
+
+	info.SetDpi(r.DPI)
+
+	r.PDF.AddPage()
+
+	// draw margins
+
+	r.PDF.SetFillColor(0, 0, 0)
+	r.PDF.Rect(page.X, page.Y, page.Width, page.Height, "FD")
+
+	// draw borders
+
+	if page.Border != nil {
+		r.PDF.SetFillColor(0, 0, 0)
+		r.PDF.Rect(page.Border.X, page.Border.Y, page.Border.Width, page.Border.Height, "FD")
+	}
+
+	// draw the image
+
+	r.PDF.ImageOptions(page.Image, page.X, page.Y, page.Width, page.Height, false, image_opts, 0, "")
+
+	// draw the caption
+
+	r.drawLines(page.Lines)
+
+	return nil
+}
+
+// registerImage registers the image in 'im_r' with the PDF document. Images are only read from their headers before being
+// registered so an error, rather than a panic or a sticky fpdf error, is returned if fpdf fails to parse an image because it
+// is corrupt or truncated.
+func (r *FPDFRenderer) registerImage(name string, image_opts fpdf.ImageOptions, im_r io.Reader) (info *fpdf.ImageInfoType, err error) {
+
+	defer func() {
+
+		if v := recover(); v != nil {
+			info = nil
+			err = fmt.Errorf("Failed to parse image, %v", v)
+		}
+	}()
+
+	info = r.PDF.RegisterImageOptionsReader(name, image_opts, im_r)
+
+	// fpdf errors are sticky, and would cause every subsequent image to fail, so clear them once they have been reported
+
+	if r.PDF.Err() {
+		err := r.PDF.Error()
+		r.PDF.ClearError()
+		return nil, err
+	}
+
+	if info == nil {
+		return nil, fmt.Errorf("Unable to determine image info")
+	}
+
+	return info, nil
+}
+
+// drawLines draws each of the lines of text in 'lines' on the current page of the PDF document.
+func (r *FPDFRenderer) drawLines(lines []*picture.PictureBookTextRun) {
+
+	font_sz, _ := r.PDF.GetFontSize()
+	defer r.PDF.SetFontSize(font_sz)
+
+	for _, ln := range lines {
+
+		r.PDF.SetFontSize(ln.Size)
+		r.PDF.SetXY(ln.X, ln.Y)
+
+		html := r.PDF.HTMLBasicNew()
+		html.Write(ln.Height, ln.Text)
+	}
+}
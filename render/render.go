@@ -0,0 +1,16 @@
+// Package render provides a common interface for drawing the pages of a picturebook, whose position and size have
+// been calculated using the `layout` package.
+package render
+
+import (
+	"context"
+
+	"github.com/aaronland/go-picturebook/picture"
+)
+
+// type Renderer provides a common interface for drawing the pages of a picturebook.
+type Renderer interface {
+	// AddPage draws 'page' as a new page. Positions and sizes are read from the page's (inch-based) X, Y, Width,
+	// Height, Border and Lines properties and images are read from its Bucket, Image and Format properties.
+	AddPage(context.Context, *picture.PictureBookPage) error
+}
@@ -8,6 +8,7 @@ import (
 	"sort"
 
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 	"github.com/aaronland/go-picturebook/picture"
 	"github.com/rwcarlsen/goexif/exif"
 	"github.com/rwcarlsen/goexif/mknote"
@@ -22,6 +23,19 @@ func init() {
 		panic(err)
 	}
 
+	err = handler.RegisterDescription(ctx, handler.SORT, &handler.Description{
+		Scheme:  "exif",
+		Summary: "Sort images, in ascending order, by their EXIF DateTime property falling back to their modification time. Images with the same time are sorted by their file size.",
+		Syntax:  "exif://",
+		Examples: []string{
+			"exif://",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
 	exif.RegisterParsers(mknote.All...)
 }
 
@@ -7,6 +7,7 @@ import (
 	"sort"
 
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 	"github.com/aaronland/go-picturebook/picture"
 )
 
@@ -18,6 +19,19 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.SORT, &handler.Description{
+		Scheme:  "modtime",
+		Summary: "Sort images, in ascending order, by their modification time. Images with the same time are sorted by their file size.",
+		Syntax:  "modtime://",
+		Examples: []string{
+			"modtime://",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // type ModTimeSorter implements the `Sorter` interface to sort a list of `picture.PictureBookPicture` by their modification dates.
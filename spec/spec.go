@@ -0,0 +1,399 @@
+// package spec provides methods for building a picturebook from a declarative specification, encoded as YAML or JSON,
+// that defines global options and an explicit, ordered list of pages.
+package spec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aaronland/go-picturebook"
+	"github.com/aaronland/go-picturebook/picture"
+	"github.com/aaronland/go-picturebook/process"
+	"github.com/aaronland/go-picturebook/progress"
+	"gopkg.in/yaml.v2"
+)
+
+// type Spec defines a struct containing a declarative specification for a picturebook.
+type Spec struct {
+	// Global options applied to the picturebook.
+	Options *Options `json:"options,omitempty" yaml:"options,omitempty"`
+	// The ordered list of pages in the picturebook.
+	Pages []*Page `json:"pages" yaml:"pages"`
+}
+
+// type Options defines a struct containing global options for a picturebook. Zero values are ignored
+// in favour of any existing values when they are applied to a `picturebook.PictureBookOptions` instance.
+type Options struct {
+	// The orientation of the picturebook. Valid options are "P" and "L" for portrait and landscape respectively.
+	Orientation string `json:"orientation,omitempty" yaml:"orientation,omitempty"`
+	// A string label corresponding to known size. Valid options are "a1", "a2", "a3", "a4", "a5", "a6", "a7", "letter", "legal" and "tabloid".
+	Size string `json:"size,omitempty" yaml:"size,omitempty"`
+	// A custom width for the picturebook.
+	Width float64 `json:"width,omitempty" yaml:"width,omitempty"`
+	// A custom height for the picturebook.
+	Height float64 `json:"height,omitempty" yaml:"height,omitempty"`
+	// The unit of measurement for the `Width` and `Height` options.
+	Units string `json:"units,omitempty" yaml:"units,omitempty"`
+	// The number dots per inch to use when calculating the size of the picturebook.
+	DPI float64 `json:"dpi,omitempty" yaml:"dpi,omitempty"`
+	// The size of any border to apply to each image in the picturebook.
+	Border float64 `json:"border,omitempty" yaml:"border,omitempty"`
+	// The size of any additional bleed to apply to the picturebook.
+	Bleed float64 `json:"bleed,omitempty" yaml:"bleed,omitempty"`
+	// The size of the margin to add to all sides of each page. If non-zero this value is used for all the other margin options.
+	Margin float64 `json:"margin,omitempty" yaml:"margin,omitempty"`
+	// The size of any margin to add to the top of each page.
+	MarginTop float64 `json:"margin_top,omitempty" yaml:"margin_top,omitempty"`
+	// The size of any margin to add to the bottom of each page.
+	MarginBottom float64 `json:"margin_bottom,omitempty" yaml:"margin_bottom,omitempty"`
+	// The size of any margin to add to the left-hand side of each page.
+	MarginLeft float64 `json:"margin_left,omitempty" yaml:"margin_left,omitempty"`
+	// The size of any margin to add to the right-hand side of each page.
+	MarginRight float64 `json:"margin_right,omitempty" yaml:"margin_right,omitempty"`
+	// A boolean value to enable to use of an OCRA font for writing captions.
+	OCRAFont bool `json:"ocra_font,omitempty" yaml:"ocra_font,omitempty"`
+	// A boolean value signaling that images should be rotated if necessary to fill the maximum amount of any given page.
+	FillPage bool `json:"fill_page,omitempty" yaml:"fill_page,omitempty"`
+}
+
+// type Page defines a struct containing the specification for a single page in a picturebook.
+type Page struct {
+	// The type of page. Valid options are "picture", "text", "blank" and "section".
+	Type string `json:"type" yaml:"type"`
+	// The path of the image, in the picturebook's `Source` bucket, to add to a "picture" page.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// The caption for a "picture" page. If empty the picturebook's `Caption` instance, if present, is used to derive a caption.
+	Caption string `json:"caption,omitempty" yaml:"caption,omitempty"`
+	// The text for a "text" page, the title for a "section" page or the text to add on the page preceding a "picture" page.
+	// For "picture" pages, if empty, the picturebook's `Text` instance, if present, is used to derive text.
+	Text string `json:"text,omitempty" yaml:"text,omitempty"`
+	// Zero or more `process.Process` URIs to apply to the image of a "picture" page. If empty the picturebook's `PreProcess` instance, if present, is used.
+	Process []string `json:"process,omitempty" yaml:"process,omitempty"`
+	// Optional layout options for a "picture" page.
+	Layout *Layout `json:"layout,omitempty" yaml:"layout,omitempty"`
+}
+
+// type Layout defines a struct containing layout options for a single page in a picturebook.
+type Layout struct {
+	// An optional value used to override the picturebook's `FillPage` option.
+	FillPage *bool `json:"fill_page,omitempty" yaml:"fill_page,omitempty"`
+}
+
+// NewSpecFromPath returns a new `Spec` instance derived from the file at 'path'. Files with a ".json" extension are
+// decoded as JSON and all other files are decoded as YAML.
+func NewSpecFromPath(ctx context.Context, path string) (*Spec, error) {
+
+	r, err := os.Open(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open %s, %w", path, err)
+	}
+
+	defer r.Close()
+
+	format := "yaml"
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		format = "json"
+	}
+
+	return NewSpecFromReader(ctx, r, format)
+}
+
+// NewSpecFromReader returns a new `Spec` instance derived from 'r' encoded as 'format'. Valid formats are "json" and "yaml".
+func NewSpecFromReader(ctx context.Context, r io.Reader, format string) (*Spec, error) {
+
+	var s *Spec
+
+	switch format {
+	case "json":
+
+		err := json.NewDecoder(r).Decode(&s)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode spec, %w", err)
+		}
+
+	case "yaml", "yml":
+
+		body, err := io.ReadAll(r)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read spec, %w", err)
+		}
+
+		err = yaml.UnmarshalStrict(body, &s)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode spec, %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("Invalid or unsupported format '%s'", format)
+	}
+
+	if s == nil {
+		return nil, fmt.Errorf("Empty spec")
+	}
+
+	err := s.Validate()
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid spec, %w", err)
+	}
+
+	return s, nil
+}
+
+// Validate ensures that each of the pages in 's' is valid.
+func (s *Spec) Validate() error {
+
+	for idx, p := range s.Pages {
+
+		switch p.Type {
+		case picture.PAGE_TYPE_PICTURE:
+
+			if p.Path == "" {
+				return fmt.Errorf("Page %d is missing a path", idx+1)
+			}
+
+		case picture.PAGE_TYPE_TEXT, picture.PAGE_TYPE_SECTION:
+
+			if p.Text == "" {
+				return fmt.Errorf("Page %d is missing text", idx+1)
+			}
+
+		case picture.PAGE_TYPE_BLANK:
+			// pass
+		default:
+			return fmt.Errorf("Page %d has invalid or unsupported type '%s'", idx+1, p.Type)
+		}
+	}
+
+	return nil
+}
+
+// Apply assigns any non-zero global options defined in 's' to 'opts'.
+func (s *Spec) Apply(opts *picturebook.PictureBookOptions) {
+
+	if s.Options == nil {
+		return
+	}
+
+	o := s.Options
+
+	if o.Orientation != "" {
+		opts.Orientation = o.Orientation
+	}
+
+	if o.Size != "" {
+		opts.Size = o.Size
+	}
+
+	if o.Width != 0.0 {
+		opts.Width = o.Width
+	}
+
+	if o.Height != 0.0 {
+		opts.Height = o.Height
+	}
+
+	if o.Units != "" {
+		opts.Units = o.Units
+	}
+
+	if o.DPI != 0.0 {
+		opts.DPI = o.DPI
+	}
+
+	if o.Border != 0.0 {
+		opts.Border = o.Border
+	}
+
+	if o.Bleed != 0.0 {
+		opts.Bleed = o.Bleed
+	}
+
+	if o.Margin != 0.0 {
+		opts.MarginTop = o.Margin
+		opts.MarginBottom = o.Margin
+		opts.MarginLeft = o.Margin
+		opts.MarginRight = o.Margin
+	}
+
+	if o.MarginTop != 0.0 {
+		opts.MarginTop = o.MarginTop
+	}
+
+	if o.MarginBottom != 0.0 {
+		opts.MarginBottom = o.MarginBottom
+	}
+
+	if o.MarginLeft != 0.0 {
+		opts.MarginLeft = o.MarginLeft
+	}
+
+	if o.MarginRight != 0.0 {
+		opts.MarginRight = o.MarginRight
+	}
+
+	if o.OCRAFont {
+		opts.OCRAFont = true
+	}
+
+	if o.FillPage {
+		opts.FillPage = true
+	}
+}
+
+// AddPages adds each of the pages defined in 's', in order, to 'pb'. The picturebook's `Filter` and `Sort` options
+// are not applied. Picture pages are added using the picturebook's `AddPicturePage` method so they are counted in its build summary,
+// and handled according to its error policy if they can not be added, the same way as pictures added using `AddPictures`.
+func (s *Spec) AddPages(ctx context.Context, pb *picturebook.PictureBook) error {
+
+	d := progress.NewDispatcher(ctx, pb.Options.Monitor)
+	defer d.Close()
+
+	tracker := progress.NewTracker(progress.STAGE_RENDER, len(s.Pages))
+	d.Signal(ctx, tracker.Event(""))
+
+	for idx, p := range s.Pages {
+
+		// Page numbers are derived from the pages which have actually been added so that skipped pictures don't leave gaps
+
+		pagenum := len(pb.Pages()) + 1
+
+		var err error
+
+		switch p.Type {
+		case picture.PAGE_TYPE_PICTURE:
+
+			fn := func(ctx context.Context, path string) (*picture.PictureBookPicture, error) {
+				return s.pictureForPage(ctx, pb, p)
+			}
+
+			err = pb.AddPicturePage(ctx, p.Path, fn)
+
+		case picture.PAGE_TYPE_TEXT:
+
+			pic := &picture.PictureBookPicture{
+				Text: p.Text,
+			}
+
+			err = pb.AddText(ctx, pagenum, pic)
+
+		case picture.PAGE_TYPE_SECTION:
+			err = pb.AddSection(ctx, pagenum, p.Text)
+		case picture.PAGE_TYPE_BLANK:
+			err = pb.AddBlankPage(ctx, pagenum)
+		default:
+			err = fmt.Errorf("Invalid or unsupported type '%s'", p.Type)
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed to add page %d, %w", idx+1, err)
+		}
+
+		d.Signal(ctx, tracker.Next(p.Path))
+	}
+
+	d.Clear()
+	return nil
+}
+
+// pictureForPage derives a `picture.PictureBookPicture` instance for 'p' deriving captions and text and applying
+// any processes as necessary.
+func (s *Spec) pictureForPage(ctx context.Context, pb *picturebook.PictureBook, p *Page) (*picture.PictureBookPicture, error) {
+
+	opts := pb.Options
+
+	caption := p.Caption
+	text_body := p.Text
+
+	if caption == "" && opts.Caption != nil {
+
+		txt, err := opts.Caption.Text(ctx, opts.Source, p.Path)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to derive caption for %s, %w", p.Path, err)
+		}
+
+		caption = txt
+	}
+
+	if text_body == "" && opts.Text != nil {
+
+		txt, err := opts.Text.Body(ctx, opts.Source, p.Path)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to derive text for %s, %w", p.Path, err)
+		}
+
+		text_body = txt
+	}
+
+	pic := &picture.PictureBookPicture{
+		Source:  p.Path,
+		Path:    p.Path,
+		Caption: caption,
+		Text:    text_body,
+	}
+
+	if p.Layout != nil {
+		pic.FillPage = p.Layout.FillPage
+	}
+
+	pr := opts.PreProcess
+
+	if len(p.Process) > 0 {
+
+		processes := make([]process.Process, len(p.Process))
+
+		for idx, process_uri := range p.Process {
+
+			current_p, err := process.NewProcess(ctx, process_uri)
+
+			if err != nil {
+				return nil, fmt.Errorf("Failed to create process '%s', %w", process_uri, err)
+			}
+
+			processes[idx] = current_p
+		}
+
+		multi_opts := &process.MultiProcessOptions{
+			Processes: processes,
+			URIs:      p.Process,
+			Cache:     opts.Cache,
+		}
+
+		multi, err := process.NewMultiProcessWithOptions(ctx, multi_opts)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create multi process, %w", err)
+		}
+
+		pr = multi
+	}
+
+	if pr != nil {
+
+		processed_path, err := pr.Transform(ctx, opts.Source, opts.Temporary, p.Path)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to process %s, %w", p.Path, err)
+		}
+
+		if processed_path != "" && processed_path != p.Path {
+			pic.Path = processed_path
+			pic.Bucket = opts.Temporary
+			pic.TempFile = processed_path
+		}
+	}
+
+	return pic, nil
+}
@@ -0,0 +1,371 @@
+package spec
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aaronland/go-picturebook"
+	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/picture"
+	"github.com/aaronland/go-picturebook/progress"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestNewSpecFromReader(t *testing.T) {
+
+	ctx := context.Background()
+
+	tests := map[string]string{
+		"yaml": `
+options:
+  size: a5
+  margin: 0.5
+pages:
+  - type: section
+    text: Part One
+  - type: picture
+    path: example.jpg
+    caption: An example
+    process:
+      - halftone://
+    layout:
+      fill_page: true
+  - type: blank
+`,
+		"json": `{"options": {"size": "a5", "margin": 0.5}, "pages": [{"type": "section", "text": "Part One"}, {"type": "picture", "path": "example.jpg", "caption": "An example", "process": ["halftone://"], "layout": {"fill_page": true}}, {"type": "blank"}]}`,
+	}
+
+	for format, body := range tests {
+
+		s, err := NewSpecFromReader(ctx, strings.NewReader(body), format)
+
+		if err != nil {
+			t.Fatalf("Failed to read %s spec, %v", format, err)
+		}
+
+		if len(s.Pages) != 3 {
+			t.Fatalf("Unexpected page count for %s spec: %d", format, len(s.Pages))
+		}
+
+		pic := s.Pages[1]
+
+		if pic.Type != picture.PAGE_TYPE_PICTURE || pic.Path != "example.jpg" || len(pic.Process) != 1 {
+			t.Fatalf("Unexpected picture page for %s spec: %v", format, pic)
+		}
+
+		if pic.Layout == nil || pic.Layout.FillPage == nil || !*pic.Layout.FillPage {
+			t.Fatalf("Unexpected layout for %s spec", format)
+		}
+
+		opts, err := picturebook.NewPictureBookDefaultOptions(ctx)
+
+		if err != nil {
+			t.Fatalf("Failed to create default options, %v", err)
+		}
+
+		s.Apply(opts)
+
+		if opts.Size != "a5" || opts.MarginTop != 0.5 || opts.MarginRight != 0.5 {
+			t.Fatalf("Unexpected options after applying %s spec: %v", format, opts)
+		}
+	}
+}
+
+func TestInvalidSpec(t *testing.T) {
+
+	ctx := context.Background()
+
+	tests := []string{
+		`pages: [{type: picture}]`,
+		`pages: [{type: text}]`,
+		`pages: [{type: chapter, text: Hello}]`,
+		`pages: [{type: blank, colour: red}]`,
+	}
+
+	for _, body := range tests {
+
+		_, err := NewSpecFromReader(ctx, strings.NewReader(body), "yaml")
+
+		if err == nil {
+			t.Fatalf("Expected spec to be invalid: %s", body)
+		}
+	}
+}
+
+// recordingMonitor implements the `progress.Monitor` interface recording each event and the number of calls to Clear.
+type recordingMonitor struct {
+	progress.Monitor
+	events []*progress.Event
+	clears int
+}
+
+func (m *recordingMonitor) Signal(ctx context.Context, ev *progress.Event) error {
+	m.events = append(m.events, ev)
+	return nil
+}
+
+func (m *recordingMonitor) Clear() error {
+	m.clears += 1
+	return nil
+}
+
+func (m *recordingMonitor) Close() error {
+	return nil
+}
+
+func TestAddPagesErrorPolicy(t *testing.T) {
+
+	ctx := context.Background()
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to register buckets, %v", err)
+	}
+
+	root := t.TempDir()
+
+	fh, err := os.Create(filepath.Join(root, "good.png"))
+
+	if err != nil {
+		t.Fatalf("Failed to create image, %v", err)
+	}
+
+	err = png.Encode(fh, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+
+	if err != nil {
+		t.Fatalf("Failed to encode image, %v", err)
+	}
+
+	err = fh.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close image, %v", err)
+	}
+
+	body := `
+pages:
+  - type: picture
+    path: missing.png
+  - type: picture
+    path: good.png
+  - type: section
+    text: The End
+`
+
+	s, err := NewSpecFromReader(ctx, strings.NewReader(body), "yaml")
+
+	if err != nil {
+		t.Fatalf("Failed to read spec, %v", err)
+	}
+
+	for _, policy := range []string{picturebook.ERROR_POLICY_SKIP, picturebook.ERROR_POLICY_STRICT} {
+
+		opts, err := picturebook.NewPictureBookDefaultOptions(ctx)
+
+		if err != nil {
+			t.Fatalf("Failed to create default options, %v", err)
+		}
+
+		opts.Source, err = bucket.NewBucket(ctx, fmt.Sprintf("file://%s", root))
+
+		if err != nil {
+			t.Fatalf("Failed to create source bucket, %v", err)
+		}
+
+		opts.Target, err = bucket.NewBucket(ctx, fmt.Sprintf("file://%s?metadata=skip", t.TempDir()))
+
+		if err != nil {
+			t.Fatalf("Failed to create target bucket, %v", err)
+		}
+
+		opts.Temporary, err = bucket.NewBucket(ctx, fmt.Sprintf("file://%s?metadata=skip", t.TempDir()))
+
+		if err != nil {
+			t.Fatalf("Failed to create temporary bucket, %v", err)
+		}
+
+		m := &recordingMonitor{}
+		opts.Monitor = m
+
+		opts.ErrorPolicy = policy
+
+		pb, err := picturebook.NewPictureBook(ctx, opts)
+
+		if err != nil {
+			t.Fatalf("Failed to create picturebook, %v", err)
+		}
+
+		err = s.AddPages(ctx, pb)
+
+		if policy == picturebook.ERROR_POLICY_STRICT {
+
+			if err == nil {
+				t.Fatalf("Expected strict error policy to fail")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Failed to add pages, %v", err)
+		}
+
+		skipped := pb.SkippedPictures()
+
+		if len(skipped) != 1 || skipped[0].Path != "missing.png" {
+			t.Fatalf("Expected missing.png to be skipped, %v", skipped)
+		}
+
+		pages := pb.Pages()
+
+		if len(pages) != 2 || pages[1].Type != picture.PAGE_TYPE_SECTION || pages[1].Page != 2 {
+			t.Fatalf("Unexpected pages, %v", pages)
+		}
+
+		// One event when rendering starts and one for each page defined in the spec, including the skipped picture
+
+		if len(m.events) != 4 || m.clears != 1 {
+			t.Fatalf("Unexpected progress events, %d events and %d clears", len(m.events), m.clears)
+		}
+
+		for idx, ev := range m.events {
+
+			if ev.Stage != progress.STAGE_RENDER || ev.Page != idx || ev.Pages != 3 {
+				t.Fatalf("Unexpected progress event %d, %v", idx, ev)
+			}
+		}
+	}
+}
+
+func TestAddPagesSummaryAndCache(t *testing.T) {
+
+	ctx := context.Background()
+
+	err := bucket.RegisterGoCloudBuckets(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to register buckets, %v", err)
+	}
+
+	root := t.TempDir()
+
+	fh, err := os.Create(filepath.Join(root, "good.png"))
+
+	if err != nil {
+		t.Fatalf("Failed to create image, %v", err)
+	}
+
+	err = png.Encode(fh, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+
+	if err != nil {
+		t.Fatalf("Failed to encode image, %v", err)
+	}
+
+	err = fh.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close image, %v", err)
+	}
+
+	body := `
+pages:
+  - type: section
+    text: Part One
+  - type: picture
+    path: good.png
+    caption: A good picture
+    text: Some words
+    process:
+      - halftone://
+  - type: picture
+    path: missing.png
+  - type: blank
+`
+
+	s, err := NewSpecFromReader(ctx, strings.NewReader(body), "yaml")
+
+	if err != nil {
+		t.Fatalf("Failed to read spec, %v", err)
+	}
+
+	opts, err := picturebook.NewPictureBookDefaultOptions(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to create default options, %v", err)
+	}
+
+	cache_root := t.TempDir()
+
+	opts.Source, err = bucket.NewBucket(ctx, fmt.Sprintf("file://%s", root))
+
+	if err != nil {
+		t.Fatalf("Failed to create source bucket, %v", err)
+	}
+
+	opts.Target, err = bucket.NewBucket(ctx, fmt.Sprintf("file://%s?metadata=skip", t.TempDir()))
+
+	if err != nil {
+		t.Fatalf("Failed to create target bucket, %v", err)
+	}
+
+	opts.Temporary, err = bucket.NewBucket(ctx, fmt.Sprintf("file://%s?metadata=skip", t.TempDir()))
+
+	if err != nil {
+		t.Fatalf("Failed to create temporary bucket, %v", err)
+	}
+
+	opts.Cache, err = bucket.NewBucket(ctx, fmt.Sprintf("file://%s?metadata=skip", cache_root))
+
+	if err != nil {
+		t.Fatalf("Failed to create cache bucket, %v", err)
+	}
+
+	pb, err := picturebook.NewPictureBook(ctx, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create picturebook, %v", err)
+	}
+
+	err = s.AddPages(ctx, pb)
+
+	if err != nil {
+		t.Fatalf("Failed to add pages, %v", err)
+	}
+
+	cached, err := os.ReadDir(cache_root)
+
+	if err != nil {
+		t.Fatalf("Failed to read cache, %v", err)
+	}
+
+	if len(cached) != 1 {
+		t.Fatalf("Expected processed picture to be cached, %d cached files", len(cached))
+	}
+
+	summary := pb.Summary()
+
+	if summary.Files != 2 || summary.Skipped != 1 || summary.Processed != 1 || summary.Captioned != 1 {
+		t.Fatalf("Unexpected summary, %d files, %d skipped, %d processed and %d captioned", summary.Files, summary.Skipped, summary.Processed, summary.Captioned)
+	}
+
+	expected := map[string]int{
+		picture.PAGE_TYPE_SECTION: 1,
+		picture.PAGE_TYPE_TEXT:    1,
+		picture.PAGE_TYPE_PICTURE: 1,
+		picture.PAGE_TYPE_BLANK:   1,
+	}
+
+	for page_type, count := range expected {
+
+		if summary.Pages[page_type] != count {
+			t.Fatalf("Expected %d %s pages, %v", count, page_type, summary.Pages)
+		}
+	}
+}
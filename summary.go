@@ -0,0 +1,137 @@
+package picturebook
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"time"
+
+	"github.com/aaronland/go-picturebook/picture"
+	"github.com/aaronland/go-picturebook/progress"
+)
+
+// type FilteredError defines the error returned by `GatherPicturesProcessFunc` functions for images which were excluded by a filter.
+// Images which are excluded by a filter are not added to a picturebook but are not considered to have been skipped.
+type FilteredError struct {
+	// The URI, or type if the URI is not known, of the filter which excluded the image.
+	Filter string
+}
+
+// Error returns a string describing the filter which excluded an image.
+func (e *FilteredError) Error() string {
+	return fmt.Sprintf("Image excluded by filter %s", e.Filter)
+}
+
+// type BuildSummary defines a struct containing counts and timings describing the creation of a picturebook.
+type BuildSummary struct {
+	// The number of files gathered from the picturebook's sources, or added using the `AddPicturePage` method.
+	Files int `json:"files"`
+	// The number of files which were skipped because they are not images.
+	NotImages int `json:"not_images"`
+	// The number of files which were skipped because of an error. See the `SkippedPictures` method for details.
+	Skipped int `json:"skipped"`
+	// The number of images excluded by each filter, keyed by the filter's URI.
+	Filtered map[string]int `json:"filtered"`
+	// The number of images transformed by the `PreProcess` option, or the processes used by `AddPicturePage`. Images for which no new file was returned are not counted.
+	Processed int `json:"processed"`
+	// The number of images with a caption.
+	Captioned int `json:"captioned"`
+	// The number of pages in the picturebook, keyed by page type (for example "picture", "text" or "blank").
+	Pages map[string]int `json:"pages"`
+	// The size, in bytes, of the saved picturebook. This is 0 if the picturebook has not been saved, was saved in debug mode or
+	// was written by an output, like HTML, which writes more than one file.
+	OutputSize int64 `json:"output_size"`
+	// The (wall) time spent in each stage (see `progress.Stages`) of creating the picturebook. Process is the time between
+	// the start of filtering, captioning and processing the first image and the end of the last one, regardless of the number
+	// of `Workers`, so it overlaps with gather, plus the time spent deriving pictures added using `AddPicturePage`. Render is the
+	// time spent adding pages. Timings are encoded as nanoseconds in JSON.
+	Timings map[string]time.Duration `json:"timings"`
+	// The time between the creation of the picturebook and the (most recent) end of saving it, or now if it has not been saved.
+	Duration time.Duration `json:"duration"`
+}
+
+// Summary returns a `BuildSummary` describing the creation of the picturebook so far. It is intended to be called after the
+// picturebook has been saved.
+func (pb *PictureBook) Summary() *BuildSummary {
+
+	pb.Mutex.Lock()
+	defer pb.Mutex.Unlock()
+
+	s := *pb.summary
+
+	s.Filtered = maps.Clone(pb.summary.Filtered)
+	s.Timings = maps.Clone(pb.summary.Timings)
+	s.Pages = make(map[string]int)
+
+	for _, page := range pb.manifest {
+		s.Pages[page.Type] += 1
+	}
+
+	for _, sk := range pb.skipped {
+
+		if errors.Is(sk.Error, ErrNotImage) {
+			s.NotImages += 1
+		} else {
+			s.Skipped += 1
+		}
+	}
+
+	if s.Duration == 0 {
+		s.Duration = time.Since(pb.created)
+	}
+
+	return &s
+}
+
+// newBuildSummary returns a new, empty, `BuildSummary` instance.
+func newBuildSummary() *BuildSummary {
+
+	s := &BuildSummary{
+		Filtered: make(map[string]int),
+		Pages:    make(map[string]int),
+		Timings:  make(map[string]time.Duration),
+	}
+
+	for _, stage := range progress.Stages {
+		s.Timings[stage] = 0
+	}
+
+	return s
+}
+
+// countPicture counts 'pic' as processed and captioned, as appropriate, in the picturebook's build summary. It is expected to be
+// called while holding `pb.Mutex`.
+func (pb *PictureBook) countPicture(pic *picture.PictureBookPicture) {
+
+	// Pictures are only counted as processed if a process returned a new (temporary) file for them
+
+	if pic.TempFile != "" {
+		pb.summary.Processed += 1
+	}
+
+	if pic.Caption != "" {
+		pb.summary.Captioned += 1
+	}
+}
+
+// timeStage adds the time since 't1' to the timing for 'stage'. It is expected to be called while holding `pb.Mutex`.
+func (pb *PictureBook) timeStage(stage string, t1 time.Time) {
+	pb.summary.Timings[stage] += time.Since(t1)
+}
+
+// type countingWriter implements the `io.Writer` interface and counts the number of bytes written to an underlying `io.Writer`.
+type countingWriter struct {
+	io.Writer
+	wr    io.Writer
+	count int64
+}
+
+// Write writes 'p' to the underlying `io.Writer` and adds the number of bytes written to the count.
+func (w *countingWriter) Write(p []byte) (int, error) {
+
+	n, err := w.wr.Write(p)
+	w.count += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,137 @@
+package picturebook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aaronland/go-picturebook/caption"
+	"github.com/aaronland/go-picturebook/filter"
+	"github.com/aaronland/go-picturebook/picture"
+	"github.com/aaronland/go-picturebook/process"
+	"github.com/aaronland/go-picturebook/progress"
+)
+
+func TestSummary(t *testing.T) {
+
+	ctx := context.Background()
+
+	root := t.TempDir()
+
+	writeTestFiles(t, root, map[string]string{
+		"keep.png":  "",
+		"skip.png":  "",
+		"notes.txt": "notes",
+	})
+
+	filter_uri := "regexp://exclude?pattern=skip"
+
+	f, err := filter.NewFilter(ctx, filter_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create filter, %v", err)
+	}
+
+	multi_filter, err := filter.NewMultiFilterWithOptions(ctx, &filter.MultiFilterOptions{
+		Filters: []filter.Filter{f},
+		URIs:    []string{filter_uri},
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to create multi filter, %v", err)
+	}
+
+	c, err := caption.NewCaption(ctx, "filename://")
+
+	if err != nil {
+		t.Fatalf("Failed to create caption, %v", err)
+	}
+
+	// The null process doesn't return a new file so images should not be counted as processed
+
+	pr, err := process.NewProcess(ctx, "null://")
+
+	if err != nil {
+		t.Fatalf("Failed to create process, %v", err)
+	}
+
+	pb, _ := newTestPictureBook(t, func(opts *PictureBookOptions) {
+		opts.Filter = multi_filter
+		opts.Caption = c
+		opts.PreProcess = pr
+	})
+
+	err = pb.AddPictures(ctx, []string{root})
+
+	if err != nil {
+		t.Fatalf("Failed to add pictures, %v", err)
+	}
+
+	err = pb.Save(ctx, "test.pdf")
+
+	if err != nil {
+		t.Fatalf("Failed to save picturebook, %v", err)
+	}
+
+	s := pb.Summary()
+
+	switch {
+	case s.Files != 3:
+		t.Fatalf("Unexpected files: %d", s.Files)
+	case s.NotImages != 1:
+		t.Fatalf("Unexpected not images: %d", s.NotImages)
+	case s.Skipped != 0:
+		t.Fatalf("Unexpected skipped: %d", s.Skipped)
+	case s.Filtered[filter_uri] != 1:
+		t.Fatalf("Unexpected filtered: %v", s.Filtered)
+	case s.Processed != 0:
+		t.Fatalf("Unexpected processed: %d", s.Processed)
+	case s.Captioned != 1:
+		t.Fatalf("Unexpected captioned: %d", s.Captioned)
+	case s.Pages[picture.PAGE_TYPE_PICTURE] != 1:
+		t.Fatalf("Unexpected pages: %v", s.Pages)
+	case s.OutputSize == 0:
+		t.Fatalf("Expected output size")
+	case s.Timings[progress.STAGE_SAVE] == 0:
+		t.Fatalf("Expected save timing")
+	}
+}
+
+func TestSummaryProcessTiming(t *testing.T) {
+
+	ctx := context.Background()
+
+	root := t.TempDir()
+
+	writeTestFiles(t, root, map[string]string{
+		"a.png": "",
+		"b.png": "",
+		"c.png": "",
+		"d.png": "",
+	})
+
+	pb, _ := newTestPictureBook(t, func(opts *PictureBookOptions) {
+		opts.Workers = 4
+	})
+
+	// Each image takes at least 100ms to process so the sum across workers is at least 400ms
+
+	process_func := pb.ProcessFunc
+
+	pb.ProcessFunc = func(ctx context.Context, path string) (*picture.PictureBookPicture, error) {
+		time.Sleep(100 * time.Millisecond)
+		return process_func(ctx, path)
+	}
+
+	_, err := pb.GatherPictures(ctx, []string{root})
+
+	if err != nil {
+		t.Fatalf("Failed to gather pictures, %v", err)
+	}
+
+	d := pb.Summary().Timings[progress.STAGE_PROCESS]
+
+	if d < 100*time.Millisecond || d >= 400*time.Millisecond {
+		t.Fatalf("Expected process timing to be wall time, got %v", d)
+	}
+}
@@ -5,24 +5,36 @@ import (
 	"context"
 	"fmt"
 	"image"
+	"io"
 
 	"github.com/aaronland/go-image/v2/encode"
 	"github.com/aaronland/go-picturebook/bucket"
 	"github.com/google/uuid"
 )
 
+// TempFileName returns a new unique filename for a temporary file ending in 'ext'.
+func TempFileName(ext string) (string, error) {
+
+	id, err := uuid.NewUUID()
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate new UUID, %w", err)
+	}
+
+	fname := fmt.Sprintf("picturebook-%s%s", id.String(), ext)
+	return fname, nil
+}
+
 // TempFileWithImage will write a new JPEG file in 'bucket' derived from 'im'. The return values are the
 // filename of the temporary file, its image format and any errors produced during writing.
 func TempFileWithImage(ctx context.Context, bucket bucket.Bucket, im image.Image) (string, string, error) {
 
-	id, err := uuid.NewUUID()
+	fname, err := TempFileName(".jpg")
 
 	if err != nil {
-		return "", "", fmt.Errorf("Failed to generate new UUID, %w", err)
+		return "", "", err
 	}
 
-	fname := fmt.Sprintf("picturebook-%s.jpg", id.String())
-
 	wr, err := bucket.NewWriter(ctx, fname, nil)
 
 	if err != nil {
@@ -43,3 +55,34 @@ func TempFileWithImage(ctx context.Context, bucket bucket.Bucket, im image.Image
 
 	return fname, "jpeg", nil
 }
+
+// TempFileWithReader will write the contents of 'r' to a new file, ending in 'ext', in 'bucket'. The return values
+// are the filename of the temporary file and any errors produced during writing.
+func TempFileWithReader(ctx context.Context, bucket bucket.Bucket, r io.Reader, ext string) (string, error) {
+
+	fname, err := TempFileName(ext)
+
+	if err != nil {
+		return "", err
+	}
+
+	wr, err := bucket.NewWriter(ctx, fname, nil)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to create new writer for temp file, %w", err)
+	}
+
+	_, err = io.Copy(wr, r)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to copy data to temp file, %w", err)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to close writer for temp file, %w", err)
+	}
+
+	return fname, nil
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/aaronland/go-picturebook/bucket"
+	"github.com/aaronland/go-picturebook/handler"
 )
 
 func init() {
@@ -18,6 +19,28 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = handler.RegisterDescription(ctx, handler.TEXT, &handler.Description{
+		Scheme:  "json",
+		Summary: "Derive texts from a JSON file, on the local disk, mapping the path of each image to its text.",
+		Syntax:  "json://{PATH}",
+		Parameters: []*handler.Parameter{
+			{
+				Name:        "path",
+				Location:    handler.PARAMETER_PATH,
+				Type:        "path",
+				Description: "The absolute path to a JSON file containing a dictionary whose keys are image paths and whose values are texts.",
+				Required:    true,
+			},
+		},
+		Examples: []string{
+			"json:///path/to/texts.json",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
 }
 
 // type JsonText implements the `Text` interface and returns empty text strings.
@@ -3,16 +3,20 @@ package text
 import (
 	"fmt"
 	"strings"
-
-	"codeberg.org/go-pdf/fpdf"
 )
 
-func PrepareText(pdf *fpdf.Fpdf, dpi float64, max_w float64, txt string) []string {
+// type StringMeasurer defines an interface for measuring the width of a string, in the current font and font size. `fpdf.Fpdf` implements this interface.
+type StringMeasurer interface {
+	// GetStringWidth returns the width of a string in the measurer's native unit of measurement (for example inches).
+	GetStringWidth(string) float64
+}
+
+func PrepareText(pdf StringMeasurer, dpi float64, max_w float64, txt string) []string {
 
 	return prepareTextWithSeparator(pdf, dpi, max_w, txt, "\n")
 }
 
-func prepareTextWithSeparator(pdf *fpdf.Fpdf, dpi float64, max_w float64, txt string, sep string) []string {
+func prepareTextWithSeparator(pdf StringMeasurer, dpi float64, max_w float64, txt string, sep string) []string {
 
 	prepped := make([]string, 0)
 
@@ -88,7 +92,7 @@ func prepareTextWithSeparator(pdf *fpdf.Fpdf, dpi float64, max_w float64, txt st
 
 }
 
-func prepareTextWithLength(pdf *fpdf.Fpdf, dpi float64, max_w float64, txt string) []string {
+func prepareTextWithLength(pdf StringMeasurer, dpi float64, max_w float64, txt string) []string {
 
 	prepped := make([]string, 0)
 